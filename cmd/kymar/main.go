@@ -1,11 +1,19 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
 
+	"github.com/pn/kymar/internal/config"
 	"github.com/pn/kymar/internal/db"
+	"github.com/pn/kymar/internal/secrets"
+	"github.com/pn/kymar/internal/ssh"
 	"github.com/pn/kymar/internal/ui"
 )
 
@@ -20,25 +28,65 @@ func main() {
 	w.Resize(fyne.NewSize(1600, 900)) // Larger initial size
 	w.CenterOnScreen()
 
+	prompter := &ui.DialogPrompter{Window: w}
+
+	// Passwords of saved connections; nil when no store could be opened
+	var store secrets.Store
+
 	// Connection handler - declare as var first to allow recursive reference
-	var handleConnection func(params db.ConnParams)
-	handleConnection = func(params db.ConnParams) {
-		// Try to connect
-		dbh, closer, err := db.Connect(params)
-		if err != nil {
-			dialog.ShowError(err, w)
-			return
-		}
+	var handleConnection func(name string, params db.ConnParams, limits db.Limits)
+	handleConnection = func(name string, params db.ConnParams, limits db.Limits) {
+		connecting := dialog.NewCustomWithoutButtons("Connecting…", widget.NewProgressBarInfinite(), w)
+		connecting.Show()
 
-		// Connection successful, show main interface
-		ui.ShowMainInterface(w, dbh, closer, params, func() {
-			// onDisconnect callback
-			ui.ShowLoginScreen(w, handleConnection)
-		})
+		// Connect off the UI goroutine so SSH prompts can be answered
+		go func() {
+			params, err := config.ResolveSecrets(store, params)
+			var dbh *sql.DB
+			var closer func() error
+			if err == nil {
+				dbh, closer, err = db.Connect(params, limits, prompter)
+			}
+			fyne.Do(func() {
+				connecting.Hide()
+				if errors.Is(err, ssh.ErrCancelled) || errors.Is(err, secrets.ErrCancelled) {
+					return
+				}
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+
+				// Connection successful, show main interface
+				ui.ShowMainInterface(w, dbh, closer, name, params, limits, func() {
+					// onDisconnect callback
+					ui.ShowLoginScreen(w, store, handleConnection)
+				})
+			})
+		}()
 	}
 
-	// Show login screen first
-	ui.ShowLoginScreen(w, handleConnection)
+	// Open the secret store and move plain-text passwords from older config
+	// files into it before the login screen loads the connections. Both may
+	// prompt for the vault master password, so run off the UI goroutine.
+	go func() {
+		var err error
+		store, err = secrets.Open(prompter.MasterPassword)
+		if err == nil {
+			var cfg *config.Config
+			if cfg, err = config.Load(); err == nil {
+				_, err = cfg.MigrateSecrets(store)
+			}
+		}
+
+		fyne.Do(func() {
+			// Show login screen first
+			ui.ShowLoginScreen(w, store, handleConnection)
+			if err != nil && !errors.Is(err, secrets.ErrCancelled) {
+				dialog.ShowError(fmt.Errorf("failed to secure saved passwords: %w", err), w)
+			}
+		})
+	}()
 
 	w.ShowAndRun()
 }
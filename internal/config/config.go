@@ -2,10 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/pn/kymar/internal/db"
+	"github.com/pn/kymar/internal/secrets"
 )
 
 // SavedConnection represents a saved database connection
@@ -13,15 +15,24 @@ type SavedConnection struct {
 	Name       string        `json:"name"`
 	Params     db.ConnParams `json:"params"`
 	IsFavorite bool          `json:"is_favorite"`
+	// Timeouts and limits; unset fields take the global defaults
+	Limits db.Limits `json:"limits"`
 }
 
 // Config holds application configuration
 type Config struct {
 	Connections []SavedConnection `json:"connections"`
+	// Defaults are the limits of connections that don't set their own
+	Defaults db.Limits `json:"defaults"`
+	// Variables are the last values entered for query parameters, by name
+	Variables map[string]db.ParamValue `json:"variables,omitempty"`
+	// Filters are the saved filters of browsed tables, by FilterKey
+	Filters map[string]db.Filter `json:"filters,omitempty"`
 }
 
-// getConfigPath returns the path to the config file
-func getConfigPath() (string, error) {
+// getConfigDir returns the directory of the application's files, creating
+// it if needed
+func getConfigDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -31,6 +42,15 @@ func getConfigPath() (string, error) {
 	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return "", err
 	}
+	return configDir, nil
+}
+
+// getConfigPath returns the path to the config file
+func getConfigPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
 
 	return filepath.Join(configDir, "connections.json"), nil
 }
@@ -90,17 +110,78 @@ func (c *Config) AddConnection(conn SavedConnection) error {
 	return c.Save()
 }
 
-// RemoveConnection removes a connection by name
-func (c *Config) RemoveConnection(name string) error {
+// RemoveConnection removes a connection by name, along with its passwords
+// in store
+func (c *Config) RemoveConnection(store secrets.Store, name string) error {
 	for i, conn := range c.Connections {
 		if conn.Name == name {
 			c.Connections = append(c.Connections[:i], c.Connections[i+1:]...)
-			return c.Save()
+			if err := c.Save(); err != nil {
+				return err
+			}
+			if conn.Params.SecretRef != "" && store != nil {
+				return store.Delete(conn.Params.SecretRef)
+			}
+			return nil
 		}
 	}
 	return nil
 }
 
+// DefaultLimits returns the global limits, completed with the built-in ones
+func (c *Config) DefaultLimits() db.Limits {
+	return c.Defaults.Or(db.DefaultLimits)
+}
+
+// LimitsFor returns the limits a saved connection runs with
+func (c *Config) LimitsFor(conn SavedConnection) db.Limits {
+	return conn.Limits.Or(c.DefaultLimits())
+}
+
+// SetDefaults replaces the global limits
+func (c *Config) SetDefaults(l db.Limits) error {
+	c.Defaults = l
+	return c.Save()
+}
+
+// SetVariables remembers the values of query parameters
+func (c *Config) SetVariables(values map[string]db.ParamValue) error {
+	if c.Variables == nil {
+		c.Variables = map[string]db.ParamValue{}
+	}
+	for name, v := range values {
+		c.Variables[name] = v
+	}
+	return c.Save()
+}
+
+// FilterKey identifies table of the database p connects to among saved
+// filters
+func FilterKey(p db.ConnParams, table string) string {
+	addr := fmt.Sprintf("%s:%d", p.Host, p.Port)
+	switch {
+	case p.File != "":
+		addr = p.File
+	case p.Socket != "":
+		addr = p.Socket
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", p.DBType, addr, p.DB, table)
+}
+
+// SetFilter saves the filter of a table, or forgets it when it has no rules
+func (c *Config) SetFilter(key string, f db.Filter) error {
+	if len(f.Active()) == 0 {
+		delete(c.Filters, key)
+		return c.Save()
+	}
+	if c.Filters == nil {
+		c.Filters = map[string]db.Filter{}
+	}
+	f.Rules = f.Active()
+	c.Filters[key] = f
+	return c.Save()
+}
+
 // GetConnection retrieves a connection by name
 func (c *Config) GetConnection(name string) *SavedConnection {
 	for _, conn := range c.Connections {
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// HistoryEntry is a statement run from the query editor
+type HistoryEntry struct {
+	Query      string        `json:"query"`
+	Connection string        `json:"connection"`
+	Database   string        `json:"database,omitempty"`
+	Time       time.Time     `json:"time"`
+	Duration   time.Duration `json:"duration"`
+	Rows       int64         `json:"rows"` // Rows read, or affected
+	Error      string        `json:"error,omitempty"`
+}
+
+// maxHistory is the number of entries kept. Older ones are dropped when the
+// history is loaded.
+const maxHistory = 5000
+
+// historyMu serializes access to the history file
+var historyMu sync.Mutex
+
+// getHistoryPath returns the path to the history file, one JSON entry per
+// line so recording a statement only appends
+func getHistoryPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "history.jsonl"), nil
+}
+
+// AppendHistory records e at the end of the history
+func AppendHistory(e HistoryEntry) error {
+	path, err := getHistoryPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadHistory reads the history, oldest entry first. Lines that don't parse,
+// e.g. one cut short by a crash, are skipped.
+func LoadHistory() ([]HistoryEntry, error) {
+	path, err := getHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) <= maxHistory {
+		return entries, nil
+	}
+
+	// Keep the newest entries only
+	entries = entries[len(entries)-maxHistory:]
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, os.WriteFile(path, b.Bytes(), 0600)
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/pn/kymar/internal/db"
+	"github.com/pn/kymar/internal/secrets"
+	"github.com/pn/kymar/internal/ssh"
+)
+
+// connectionSecrets is what a connection keeps in the secret store
+type connectionSecrets struct {
+	Pass     string   `json:"pass,omitempty"`
+	SSHPass  string   `json:"ssh_pass,omitempty"`
+	JumpPass []string `json:"jump_pass,omitempty"`
+}
+
+// secretKey names the secret of a saved connection. Saving a connection under
+// the same name replaces its secret.
+func secretKey(name string) string {
+	return "connection:" + name
+}
+
+// takeSecrets returns the passwords of p and p with them cleared
+func takeSecrets(p db.ConnParams) (connectionSecrets, db.ConnParams) {
+	s := connectionSecrets{Pass: p.Pass, SSHPass: p.SSHPass}
+	p.Pass, p.SSHPass = "", ""
+
+	if len(p.SSHJumps) == 0 {
+		return s, p
+	}
+	jumps := make([]ssh.Hop, len(p.SSHJumps))
+	pass := make([]string, len(p.SSHJumps))
+	for i, hop := range p.SSHJumps {
+		pass[i] = hop.Auth.Password
+		if pass[i] != "" {
+			s.JumpPass = pass
+		}
+		hop.Auth.Password = ""
+		jumps[i] = hop
+	}
+	p.SSHJumps = jumps
+	return s, p
+}
+
+func (s connectionSecrets) empty() bool {
+	return s.Pass == "" && s.SSHPass == "" && len(s.JumpPass) == 0
+}
+
+// StoreSecrets moves the passwords of the connection called name into store
+// and returns the params to save, which never contain passwords. oldRef is
+// the SecretRef the connection was saved with before, if any. On error the
+// passwords are dropped rather than written in plain text. Without a store,
+// passwords are not saved at all.
+//
+// The vault may ask for its master password, so don't call this on the UI
+// goroutine.
+func StoreSecrets(store secrets.Store, name string, p db.ConnParams, oldRef string) (db.ConnParams, error) {
+	s, p := takeSecrets(p)
+	p.SecretRef = ""
+	if s.empty() {
+		if oldRef == "" || store == nil {
+			return p, nil
+		}
+		// Drop what the earlier save stored
+		return p, store.Delete(oldRef)
+	}
+	if store == nil {
+		return p, errors.New("no secret store available")
+	}
+
+	key := secretKey(name)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return p, err
+	}
+	if err := store.Set(key, string(data)); err != nil {
+		return p, err
+	}
+	p.SecretRef = key
+	return p, nil
+}
+
+// ResolveSecrets fills in the passwords of a saved connection from store.
+// Params without a SecretRef are returned unchanged, as are those whose
+// secret has gone missing. Like StoreSecrets it may prompt.
+func ResolveSecrets(store secrets.Store, p db.ConnParams) (db.ConnParams, error) {
+	if p.SecretRef == "" || store == nil {
+		return p, nil
+	}
+
+	data, err := store.Get(p.SecretRef)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+
+	var s connectionSecrets
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return p, err
+	}
+	p.Pass, p.SSHPass = s.Pass, s.SSHPass
+
+	if len(s.JumpPass) > 0 {
+		jumps := make([]ssh.Hop, len(p.SSHJumps))
+		copy(jumps, p.SSHJumps)
+		for i := range jumps {
+			if i < len(s.JumpPass) {
+				jumps[i].Auth.Password = s.JumpPass[i]
+			}
+		}
+		p.SSHJumps = jumps
+	}
+	return p, nil
+}
+
+// MigrateSecrets moves plain-text passwords left in the config file by older
+// versions into store and saves the result. It reports whether the
+// file changed. Connections that fail to migrate keep their passwords, so
+// nothing is lost when the vault is not unlocked; the next start tries again.
+func (c *Config) MigrateSecrets(store secrets.Store) (bool, error) {
+	changed := false
+	for i, conn := range c.Connections {
+		if s, _ := takeSecrets(conn.Params); s.empty() {
+			continue
+		}
+		p, err := StoreSecrets(store, conn.Name, conn.Params, conn.Params.SecretRef)
+		if err != nil {
+			if changed {
+				return true, errors.Join(err, c.Save())
+			}
+			return false, err
+		}
+		c.Connections[i].Params = p
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, c.Save()
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/pn/kymar/internal/db"
+	"github.com/pn/kymar/internal/secrets"
+)
+
+// memoryStore is a secrets.Store in a map that records deletions
+type memoryStore struct {
+	items   map[string]string
+	deleted []string
+}
+
+func (s *memoryStore) Name() string { return "memory" }
+
+func (s *memoryStore) Get(key string) (string, error) {
+	v, ok := s.items[key]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *memoryStore) Set(key, value string) error {
+	s.items[key] = value
+	return nil
+}
+
+func (s *memoryStore) Delete(key string) error {
+	s.deleted = append(s.deleted, key)
+	delete(s.items, key)
+	return nil
+}
+
+func TestStoreSecrets(t *testing.T) {
+	store := &memoryStore{items: map[string]string{}}
+
+	p, err := StoreSecrets(store, "prod", db.ConnParams{Host: "db", Pass: "pw"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Pass != "" || p.SecretRef == "" {
+		t.Fatalf("saved params = %+v, want the password replaced by a SecretRef", p)
+	}
+	resolved, err := ResolveSecrets(store, p)
+	if err != nil || resolved.Pass != "pw" {
+		t.Fatalf("ResolveSecrets = %q, %v; want pw", resolved.Pass, err)
+	}
+
+	// Without passwords, only an earlier secret is deleted
+	if _, err := StoreSecrets(store, "file", db.ConnParams{File: "x.db"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.deleted) != 0 {
+		t.Fatalf("deleted %v for a connection that never had a secret", store.deleted)
+	}
+	if _, err := StoreSecrets(store, "prod", db.ConnParams{Host: "db"}, p.SecretRef); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != p.SecretRef {
+		t.Fatalf("deleted %v, want [%s]", store.deleted, p.SecretRef)
+	}
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"slices"
+	"strings"
+)
+
+// topWords returns the keywords and bare identifiers of stmt outside of
+// parentheses, upper-cased. A statement wrapped in parentheses, such as
+// (SELECT ...) UNION (SELECT ...), counts as top level.
+func topWords(stmt string, syntax Syntax) []string {
+	var words []string
+	l := newLexer(stmt, syntax)
+	depth, base := 0, -1 // base is the depth of the first word
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return words
+		}
+		switch {
+		case tok.kind == tokPunct && tok.text == "(":
+			depth++
+		case tok.kind == tokPunct && tok.text == ")":
+			depth--
+		case tok.kind == tokWord:
+			if base < 0 {
+				base = depth
+			}
+			if depth <= base {
+				words = append(words, strings.ToUpper(tok.text))
+			}
+		}
+	}
+}
+
+// Keyword returns the leading keyword of stmt, upper-cased, skipping comments
+// and opening parentheses. It returns "" if there is none.
+func Keyword(stmt string, syntax Syntax) string {
+	l := newLexer(stmt, syntax)
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return ""
+		}
+		switch {
+		case tok.kind == tokWord:
+			return strings.ToUpper(tok.text)
+		case tok.kind == tokSpace, tok.kind == tokComment, tok.kind == tokPunct && tok.text == "(":
+		default:
+			return ""
+		}
+	}
+}
+
+// ReturnsRows reports whether stmt produces a result grid and should be run
+// as a query rather than executed
+func ReturnsRows(stmt string, syntax Syntax) bool {
+	words := topWords(stmt, syntax)
+	if len(words) == 0 {
+		return false
+	}
+	if words[0] == "WITH" {
+		// The main statement follows the common table expressions, whose
+		// bodies are parenthesised
+		for i, w := range words[1:] {
+			switch w {
+			case "SELECT", "VALUES", "TABLE", "INSERT", "UPDATE", "DELETE", "REPLACE", "MERGE":
+				return returnsRows(words[i+1:], syntax)
+			}
+		}
+		return false
+	}
+	return returnsRows(words, syntax)
+}
+
+func returnsRows(words []string, syntax Syntax) bool {
+	switch words[0] {
+	case "SELECT":
+		// SELECT ... INTO stores the rows in a table, variables or a file
+		return !slices.Contains(words, "INTO")
+	case "VALUES", "TABLE", "SHOW", "DESC", "DESCRIBE", "EXPLAIN", "PRAGMA", "CALL", "FETCH", "HELP":
+		return true
+	case "INSERT", "UPDATE", "DELETE", "REPLACE", "MERGE":
+		return slices.Contains(words, "RETURNING")
+	case "ANALYZE", "CHECK", "CHECKSUM", "OPTIMIZE", "REPAIR":
+		return syntax.TableStatusResults
+	}
+	return false
+}
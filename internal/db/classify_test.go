@@ -0,0 +1,73 @@
+package db
+
+import "testing"
+
+// syntaxOf returns the syntax of the dialect registered under name
+func syntaxOf(t *testing.T, name string) Syntax {
+	t.Helper()
+	d, err := GetDialect(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.Syntax()
+}
+
+func TestReturnsRows(t *testing.T) {
+	tests := []struct {
+		dialect string
+		stmt    string
+		want    bool
+	}{
+		{"sqlite", "SELECT 1", true},
+		{"sqlite", "  -- comment\n/* block */ select 1", true},
+		{"sqlite", "(SELECT 1) UNION (SELECT 2)", true},
+		{"sqlite", "VALUES (1), (2)", true},
+		{"sqlite", "PRAGMA table_info(t)", true},
+		{"sqlite", "EXPLAIN QUERY PLAN SELECT 1", true},
+		{"sqlite", "CREATE TABLE t (id INTEGER)", false},
+		{"sqlite", "", false},
+		{"sqlite", "-- only a comment", false},
+		{"mysql", "SHOW TABLES", true},
+		{"mysql", "DESC t", true},
+		{"mysql", "CALL p()", true},
+		{"mysql", "SELECT a INTO @a FROM t", false},
+		{"mysql", "SELECT (SELECT a FROM t LIMIT 1) AS x", true},
+		{"mysql", "OPTIMIZE TABLE t", true},
+		{"postgres", "ANALYZE t", false},
+		{"postgres", "SELECT * INTO copy FROM t", false},
+		{"postgres", "INSERT INTO t VALUES (1)", false},
+		{"postgres", "INSERT INTO t VALUES (1) RETURNING id", true},
+		{"postgres", "UPDATE t SET a = 1 WHERE id = 2 returning *", true},
+		{"postgres", "DELETE FROM t WHERE a = 'RETURNING'", false},
+		{"postgres", "DELETE FROM t -- RETURNING\nWHERE id = 1", false},
+		{"postgres", "WITH x AS (SELECT 1) SELECT * FROM x", true},
+		{"postgres", "WITH RECURSIVE r(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM r) SELECT n FROM r", true},
+		{"postgres", "WITH x AS (SELECT 1 AS a) INSERT INTO t SELECT a FROM x", false},
+		{"postgres", "WITH x AS (SELECT 1 AS a) INSERT INTO t SELECT a FROM x RETURNING id", true},
+		{"postgres", "WITH d AS (DELETE FROM t RETURNING *) INSERT INTO log SELECT * FROM d", false},
+		{"postgres", "WITH d AS (DELETE FROM t RETURNING *) SELECT count(*) FROM d", true},
+	}
+	for _, tt := range tests {
+		if got := ReturnsRows(tt.stmt, syntaxOf(t, tt.dialect)); got != tt.want {
+			t.Errorf("%s: ReturnsRows(%q) = %v, want %v", tt.dialect, tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestKeyword(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{"use db", "USE"},
+		{"/* hint */ (select 1)", "SELECT"},
+		{"-- comment\nINSERT INTO t VALUES (1)", "INSERT"},
+		{"'text'", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Keyword(tt.stmt, syntaxOf(t, "sqlite")); got != tt.want {
+			t.Errorf("Keyword(%q) = %q, want %q", tt.stmt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Queryer is the subset of *sql.DB, *sql.Conn and *sql.Tx used by dialects
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// TableInfo holds table metadata shown in the sidebar. Fields a dialect
+// cannot provide are left invalid.
+type TableInfo struct {
+	Created       sql.NullTime
+	Engine        sql.NullString
+	Rows          sql.NullInt64
+	Size          sql.NullInt64  // Total size in bytes
+	SizePretty    sql.NullString // Server-formatted size, used when Size is unknown
+	Encoding      sql.NullString
+	AutoIncrement sql.NullInt64
+	Comment       sql.NullString
+}
+
+// Dialect encapsulates everything that differs between database engines
+type Dialect interface {
+	// Name is the identifier stored in ConnParams.DBType
+	Name() string
+	// DisplayName is the label shown in the connection forms
+	DisplayName() string
+	DefaultPort() int
+	DefaultUser() string
+
+	// DriverName is the database/sql driver to open
+	DriverName() string
+	// DSN builds the data source name for p
+	DSN(p ConnParams) string
+
+	// Syntax describes the dialect's lexical rules for scripts
+	Syntax() Syntax
+	// QuoteIdent quotes a table or column name
+	QuoteIdent(name string) string
+	// ListTables returns the tables of the current database
+	ListTables(ctx context.Context, q Queryer) ([]string, error)
+	// PrimaryKey returns the primary key columns of table in key order
+	PrimaryKey(ctx context.Context, q Queryer, table string) ([]string, error)
+	// TableInfo returns metadata about table
+	TableInfo(ctx context.Context, q Queryer, table string) (TableInfo, error)
+	// SelectQuery builds the query browsing a page of a table. Its
+	// parameters take the values of p.Params.
+	SelectQuery(p Page) string
+}
+
+// DatabaseSwitcher is implemented by dialects that can list and switch
+// databases on an open connection (e.g. MySQL's USE)
+type DatabaseSwitcher interface {
+	ListDatabases(ctx context.Context, q Queryer) ([]string, error)
+	UseDatabase(ctx context.Context, q Queryer, name string) error
+	// CurrentDatabase returns the selected database, or "" if none is
+	CurrentDatabase(ctx context.Context, q Queryer) (string, error)
+}
+
+// QueryCanceller is implemented by dialects whose server can abort a query
+// running on another connection
+type QueryCanceller interface {
+	// BackendID returns the server's id for the connection q runs on
+	BackendID(ctx context.Context, q Queryer) (int64, error)
+	// CancelQuery stops the statement running on backend id. q must be a
+	// different connection.
+	CancelQuery(ctx context.Context, q Queryer, id int64) error
+}
+
+// StatementTimeouter is implemented by dialects whose server can enforce a
+// statement timeout for the session itself
+type StatementTimeouter interface {
+	// SetStatementTimeout limits statements run on q to d; 0 lifts the limit
+	SetStatementTimeout(ctx context.Context, q Queryer, d time.Duration) error
+}
+
+// DialFunc opens the network connection to a database server
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ConnectorDialect is implemented by dialects whose driver accepts a custom
+// dial function, which SSH traffic is routed through. Only these dialects
+// can be reached over SSH.
+type ConnectorDialect interface {
+	// Connector builds a connector for p. dial is nil for direct connections.
+	Connector(p ConnParams, dial DialFunc) (driver.Connector, error)
+}
+
+// FileDialect is implemented by embedded engines that open a local file
+// instead of connecting to a server
+type FileDialect interface {
+	// FileExtensions lists the extensions offered by the file picker
+	FileExtensions() []string
+}
+
+// SocketDialect is implemented by dialects that can connect over a Unix socket
+type SocketDialect interface {
+	// DefaultSocket is the usual socket location for the engine
+	DefaultSocket() string
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[string]Dialect{}
+	// dialectOrder keeps registration order for stable UI listings
+	dialectOrder []string
+)
+
+// RegisterDialect makes a dialect available by name. It panics if called
+// twice with the same name, mirroring sql.Register.
+func RegisterDialect(d Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+
+	if _, dup := dialects[d.Name()]; dup {
+		panic("db: RegisterDialect called twice for " + d.Name())
+	}
+	dialects[d.Name()] = d
+	dialectOrder = append(dialectOrder, d.Name())
+}
+
+// GetDialect returns the dialect registered under name
+func GetDialect(name string) (Dialect, error) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+
+	d, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type %q", name)
+	}
+	return d, nil
+}
+
+// Dialects returns all registered dialects in registration order
+func Dialects() []Dialect {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+
+	out := make([]Dialect, 0, len(dialectOrder))
+	for _, name := range dialectOrder {
+		out = append(out, dialects[name])
+	}
+	return out
+}
+
+// DialectByDisplayName returns the dialect shown as label in the UI
+func DialectByDisplayName(label string) (Dialect, error) {
+	for _, d := range Dialects() {
+		if d.DisplayName() == label {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported database type %q", label)
+}
+
+// Page describes a page of rows browsed from a table. Pages are found by
+// OFFSET, or by keyset when OrderBy is the primary key: the page then starts
+// right after the row whose OrderBy columns hold Key, or with Before, ends
+// right before it.
+type Page struct {
+	Table     string
+	OrderBy   []string // May be empty
+	Direction string   // "ASC" or "DESC"
+	Limit     int
+	Offset    int
+	Key       []any
+	Before    bool
+	Last      bool   // The last page, when keyset paging
+	Filter    Filter // Only matching rows are paged
+}
+
+// Params returns the values of the parameters of the page's query
+func (p Page) Params() map[string]any {
+	_, params := p.Filter.where(func(col string) string { return col })
+	for i, v := range p.Key {
+		params[fmt.Sprintf(":key%d", i+1)] = v
+	}
+	return params
+}
+
+// selectQuery builds the LIMIT-style browse query shared by most dialects.
+// Pages before a key and the last page are read backwards in a subquery,
+// then sorted back.
+func selectQuery(d Dialect, p Page) string {
+	direction := "ASC"
+	if p.Direction == "DESC" {
+		direction = "DESC"
+	}
+	backwards := (p.Before || p.Last) && len(p.OrderBy) > 0
+	scan := direction
+	if backwards && direction == "ASC" {
+		scan = "DESC"
+	} else if backwards {
+		scan = "ASC"
+	}
+	orderBy := func(dir string) string {
+		cols := make([]string, len(p.OrderBy))
+		for i, col := range p.OrderBy {
+			cols[i] = d.QuoteIdent(col) + " " + dir
+		}
+		return " ORDER BY " + strings.Join(cols, ", ")
+	}
+
+	var conds []string
+	if filter, _ := p.Filter.where(d.QuoteIdent); filter != "" {
+		conds = append(conds, filter)
+	}
+	if len(p.Key) > 0 && len(p.Key) == len(p.OrderBy) {
+		cols := make([]string, len(p.OrderBy))
+		params := make([]string, len(p.Key))
+		for i, col := range p.OrderBy {
+			cols[i] = d.QuoteIdent(col)
+			params[i] = fmt.Sprintf(":key%d", i+1)
+		}
+		op := ">"
+		if scan == "DESC" {
+			op = "<"
+		}
+		if len(cols) == 1 {
+			conds = append(conds, fmt.Sprintf("%s %s %s", cols[0], op, params[0]))
+		} else {
+			// Row values compare like a composite key sorts
+			conds = append(conds, fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), op, strings.Join(params, ", ")))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT * FROM %s", d.QuoteIdent(p.Table))
+	if len(conds) > 0 {
+		b.WriteString(" WHERE " + strings.Join(conds, " AND "))
+	}
+	if len(p.OrderBy) > 0 {
+		b.WriteString(orderBy(scan))
+	}
+	if p.Limit > 0 {
+		fmt.Fprintf(&b, " LIMIT %d", p.Limit)
+	}
+	if p.Offset > 0 {
+		fmt.Fprintf(&b, " OFFSET %d", p.Offset)
+	}
+	query := b.String()
+	if backwards {
+		query = "SELECT * FROM (" + query + ") AS page" + orderBy(direction)
+	}
+	return query + ";"
+}
+
+// scanStrings reads a single string column from every row
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDialectRegistry(t *testing.T) {
+	var names []string
+	for _, d := range Dialects() {
+		names = append(names, d.Name())
+	}
+	if want := []string{"mysql", "postgres", "sqlite"}; !slices.Equal(names, want) {
+		t.Errorf("Dialects = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		name, display string
+	}{
+		{"mysql", "MySQL"},
+		{"postgres", "PostgreSQL"},
+		{"sqlite", "SQLite"},
+	}
+	for _, tt := range tests {
+		d, err := GetDialect(tt.name)
+		if err != nil || d.Name() != tt.name {
+			t.Errorf("GetDialect(%q) = %v, %v", tt.name, d, err)
+			continue
+		}
+		if byLabel, err := DialectByDisplayName(tt.display); err != nil || byLabel.Name() != tt.name {
+			t.Errorf("DialectByDisplayName(%q) = %v, %v; want %s", tt.display, byLabel, err, tt.name)
+		}
+	}
+
+	if _, err := GetDialect("oracle"); err == nil {
+		t.Error("GetDialect of an unknown name succeeded")
+	}
+	if _, err := DialectByDisplayName("mysql"); err == nil {
+		t.Error("DialectByDisplayName of a name rather than a label succeeded")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a dialect twice didn't panic")
+		}
+	}()
+	RegisterDialect(sqliteDialect{})
+}
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		dialect string
+		name    string
+		want    string
+	}{
+		{"mysql", "users", "`users`"},
+		{"mysql", "we`ird", "`we``ird`"},
+		{"postgres", "users", `"users"`},
+		{"postgres", `we"ird`, `"we""ird"`},
+		{"sqlite", "order", `"order"`},
+		{"sqlite", `a"b`, `"a""b"`},
+	}
+	for _, tt := range tests {
+		d, err := GetDialect(tt.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.QuoteIdent(tt.name); got != tt.want {
+			t.Errorf("%s: QuoteIdent(%q) = %s, want %s", tt.dialect, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		params  ConnParams
+		want    string
+	}{
+		{
+			name:    "mysql tcp",
+			dialect: "mysql",
+			params:  ConnParams{Host: "db", Port: 3306, User: "root", Pass: "pw", DB: "app"},
+			want:    "root:pw@tcp(db:3306)/app?parseTime=true&multiStatements=true&clientFoundRows=true",
+		},
+		{
+			name:    "mysql socket, preferred TLS",
+			dialect: "mysql",
+			params:  ConnParams{Socket: "/tmp/mysql.sock", User: "root", SSLMode: SSLPrefer},
+			want:    "root:@unix(/tmp/mysql.sock)/?parseTime=true&multiStatements=true&clientFoundRows=true&tls=preferred",
+		},
+		{
+			name:    "mysql verified TLS",
+			dialect: "mysql",
+			params:  ConnParams{Host: "db", Port: 3306, User: "u", SSLMode: SSLVerifyFull},
+			want: "u:@tcp(db:3306)/?parseTime=true&multiStatements=true&clientFoundRows=true&tls=" +
+				mysqlTLSKey(ConnParams{Host: "db", Port: 3306, User: "u", SSLMode: SSLVerifyFull}),
+		},
+		{
+			name:    "postgres",
+			dialect: "postgres",
+			params:  ConnParams{Host: "db", Port: 5433, User: "postgres", Pass: "pw", DB: "app"},
+			want:    "host=db port=5433 user=postgres password=pw sslmode=disable dbname=app",
+		},
+		{
+			name:    "postgres quoting and default port",
+			dialect: "postgres",
+			params:  ConnParams{Host: "db", User: "me", Pass: `it's a \ pass`},
+			want:    `host=db port=5432 user=me password='it\'s a \\ pass' sslmode=disable`,
+		},
+		{
+			name:    "postgres empty password",
+			dialect: "postgres",
+			params:  ConnParams{Host: "db", Port: 5432, User: "me"},
+			want:    "host=db port=5432 user=me password='' sslmode=disable",
+		},
+		{
+			name:    "postgres socket file",
+			dialect: "postgres",
+			params:  ConnParams{Socket: "/var/run/postgresql/.s.PGSQL.5433", User: "me"},
+			want:    "host=/var/run/postgresql port=5433 user=me password='' sslmode=disable",
+		},
+		{
+			name:    "postgres prefer and certificates",
+			dialect: "postgres",
+			params:  ConnParams{Host: "db", Port: 5432, User: "me", SSLMode: SSLPrefer, SSLCA: "/ca.pem", SSLCert: "/c.pem", SSLKey: "/k.pem"},
+			want:    "host=db port=5432 user=me password='' sslmode=require sslrootcert=/ca.pem sslcert=/c.pem sslkey=/k.pem",
+		},
+		{
+			name:    "postgres certificates unused without TLS",
+			dialect: "postgres",
+			params:  ConnParams{Host: "db", Port: 5432, User: "me", SSLMode: SSLDisable, SSLCA: "/ca.pem"},
+			want:    "host=db port=5432 user=me password='' sslmode=disable",
+		},
+		{
+			name:    "sqlite",
+			dialect: "sqlite",
+			params:  ConnParams{File: "/data/my app.db"},
+			want:    "file:///data/my%20app.db?mode=rw&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)",
+		},
+	}
+	for _, tt := range tests {
+		d, err := GetDialect(tt.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.DSN(tt.params); got != tt.want {
+			t.Errorf("%s: DSN =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
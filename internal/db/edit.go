@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Change is a statement editing table data, with its arguments
+type Change struct {
+	Query string
+	Args  []any
+	// SingleRow is set when the statement addresses one row by its key, and
+	// so must affect exactly one
+	SingleRow bool
+}
+
+// exec runs c on q. A change addressing one row fails unless it affected
+// exactly one, e.g. when the row was deleted or its key changed meanwhile.
+func (c Change) exec(ctx context.Context, q Queryer) error {
+	res, err := q.ExecContext(ctx, c.Query, c.Args...)
+	if err != nil || !c.SingleRow {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("%s matched %d rows instead of 1; the row may have changed since it was read", c.Query, n)
+	}
+	return nil
+}
+
+// placeholder returns the n-th (1-based) parameter placeholder of d
+func placeholder(d Dialect, n int) string {
+	if d.Syntax().NumberedParams {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// UpdateRow builds the UPDATE setting columns to values in the row of table
+// whose key columns hold keyValues
+func UpdateRow(d Dialect, table string, columns []string, values []any, key []string, keyValues []any) Change {
+	var b strings.Builder
+	fmt.Fprintf(&b, "UPDATE %s SET ", d.QuoteIdent(table))
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s = %s", d.QuoteIdent(col), placeholder(d, i+1))
+	}
+	b.WriteString(" WHERE ")
+	for i, col := range key {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		fmt.Fprintf(&b, "%s = %s", d.QuoteIdent(col), placeholder(d, len(columns)+i+1))
+	}
+	return Change{Query: b.String(), Args: append(append([]any{}, values...), keyValues...), SingleRow: true}
+}
+
+// InsertRow builds the INSERT of a row into table with values for columns.
+// Without columns every column takes its default.
+func InsertRow(d Dialect, table string, columns []string, values []any) Change {
+	if len(columns) == 0 {
+		if d.Syntax().EmptyValuesList {
+			return Change{Query: fmt.Sprintf("INSERT INTO %s () VALUES ()", d.QuoteIdent(table))}
+		}
+		return Change{Query: fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", d.QuoteIdent(table))}
+	}
+	quoted := make([]string, len(columns))
+	params := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.QuoteIdent(col)
+		params[i] = placeholder(d, i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		d.QuoteIdent(table), strings.Join(quoted, ", "), strings.Join(params, ", "))
+	return Change{Query: query, Args: values}
+}
+
+// DeleteRow builds the DELETE of the row of table whose key columns hold
+// keyValues
+func DeleteRow(d Dialect, table string, key []string, keyValues []any) Change {
+	var b strings.Builder
+	fmt.Fprintf(&b, "DELETE FROM %s WHERE ", d.QuoteIdent(table))
+	for i, col := range key {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		fmt.Fprintf(&b, "%s = %s", d.QuoteIdent(col), placeholder(d, i+1))
+	}
+	return Change{Query: b.String(), Args: keyValues, SingleRow: true}
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FilterOp is the comparison of a filter rule
+type FilterOp string
+
+const (
+	OpEqual    FilterOp = "="
+	OpNotEqual FilterOp = "!="
+	OpLike     FilterOp = "LIKE"
+	OpIn       FilterOp = "IN"
+	OpIsNull   FilterOp = "IS NULL"
+	OpBetween  FilterOp = "BETWEEN"
+	OpGreater  FilterOp = ">"
+	OpLess     FilterOp = "<"
+)
+
+// FilterOps lists the filter operators in the order the UI offers them
+var FilterOps = []FilterOp{OpEqual, OpNotEqual, OpLike, OpIn, OpIsNull, OpBetween, OpGreater, OpLess}
+
+// FilterRule compares a column with a value. IN takes a comma-separated
+// list, BETWEEN takes its upper bound in Value2 and IS NULL takes no value.
+type FilterRule struct {
+	Column string   `json:"column"`
+	Op     FilterOp `json:"op"`
+	Value  string   `json:"value,omitempty"`
+	Value2 string   `json:"value2,omitempty"`
+}
+
+// Filter is a set of rules that rows of a browsed table must all match
+// (Any false) or at least one of (Any true)
+type Filter struct {
+	Rules []FilterRule `json:"rules"`
+	Any   bool         `json:"any,omitempty"`
+}
+
+// Active returns the rules with a column, which are the ones applied
+func (f Filter) Active() []FilterRule {
+	var rules []FilterRule
+	for _, r := range f.Rules {
+		if r.Column != "" {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// where builds the condition of f, with :filterN parameters, and their
+// values. It is empty when no rule is active, and parenthesized when it
+// ORs several rules, so it can be ANDed with other conditions.
+func (f Filter) where(quote func(string) string) (string, map[string]any) {
+	params := map[string]any{}
+	param := func(v string) string {
+		name := fmt.Sprintf(":filter%d", len(params)+1)
+		params[name] = v
+		return name
+	}
+
+	var conds []string
+	for _, r := range f.Active() {
+		col := quote(r.Column)
+		switch r.Op {
+		case OpIsNull:
+			conds = append(conds, col+" IS NULL")
+		case OpIn:
+			var list []string
+			for _, v := range strings.Split(r.Value, ",") {
+				list = append(list, param(strings.TrimSpace(v)))
+			}
+			conds = append(conds, fmt.Sprintf("%s IN (%s)", col, strings.Join(list, ", ")))
+		case OpBetween:
+			conds = append(conds, fmt.Sprintf("%s BETWEEN %s AND %s", col, param(r.Value), param(r.Value2)))
+		case OpNotEqual:
+			conds = append(conds, fmt.Sprintf("%s <> %s", col, param(r.Value)))
+		case OpLike, OpGreater, OpLess:
+			conds = append(conds, fmt.Sprintf("%s %s %s", col, r.Op, param(r.Value)))
+		default:
+			conds = append(conds, fmt.Sprintf("%s = %s", col, param(r.Value)))
+		}
+	}
+	if len(conds) == 0 {
+		return "", params
+	}
+	if f.Any && len(conds) > 1 {
+		return "(" + strings.Join(conds, " OR ") + ")", params
+	}
+	return strings.Join(conds, " AND "), params
+}
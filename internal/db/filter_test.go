@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// openTestDB opens a new SQLite database holding table t with rows (1, 'a')
+// and (2, 'b')
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dbh, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbh.Close() })
+	if _, err := dbh.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO t VALUES (1, 'a'), (2, 'b')"); err != nil {
+		t.Fatal(err)
+	}
+	return dbh
+}
+
+func TestFilterWhere(t *testing.T) {
+	quote := func(col string) string { return `"` + col + `"` }
+	tests := []struct {
+		name       string
+		filter     Filter
+		wantWhere  string
+		wantParams map[string]any
+	}{
+		{
+			name:       "no active rules",
+			filter:     Filter{Rules: []FilterRule{{Op: OpEqual, Value: "x"}}},
+			wantParams: map[string]any{},
+		},
+		{
+			name:       "equal",
+			filter:     Filter{Rules: []FilterRule{{Column: "a", Op: OpEqual, Value: "1"}}},
+			wantWhere:  `"a" = :filter1`,
+			wantParams: map[string]any{":filter1": "1"},
+		},
+		{
+			name:       "IN list",
+			filter:     Filter{Rules: []FilterRule{{Column: "a", Op: OpIn, Value: "x, y ,z"}}},
+			wantWhere:  `"a" IN (:filter1, :filter2, :filter3)`,
+			wantParams: map[string]any{":filter1": "x", ":filter2": "y", ":filter3": "z"},
+		},
+		{
+			name:       "BETWEEN",
+			filter:     Filter{Rules: []FilterRule{{Column: "d", Op: OpBetween, Value: "2024-01-01", Value2: "2024-12-31"}}},
+			wantWhere:  `"d" BETWEEN :filter1 AND :filter2`,
+			wantParams: map[string]any{":filter1": "2024-01-01", ":filter2": "2024-12-31"},
+		},
+		{
+			name:       "IS NULL takes no value",
+			filter:     Filter{Rules: []FilterRule{{Column: "a", Op: OpIsNull, Value: "ignored"}}},
+			wantWhere:  `"a" IS NULL`,
+			wantParams: map[string]any{},
+		},
+		{
+			name: "all rules",
+			filter: Filter{Rules: []FilterRule{
+				{Column: "a", Op: OpNotEqual, Value: "1"},
+				{Column: "b", Op: OpLike, Value: "x%"},
+			}},
+			wantWhere:  `"a" <> :filter1 AND "b" LIKE :filter2`,
+			wantParams: map[string]any{":filter1": "1", ":filter2": "x%"},
+		},
+		{
+			name: "any rule",
+			filter: Filter{Any: true, Rules: []FilterRule{
+				{Column: "a", Op: OpGreater, Value: "1"},
+				{Column: "b", Op: OpIsNull},
+			}},
+			wantWhere:  `("a" > :filter1 OR "b" IS NULL)`,
+			wantParams: map[string]any{":filter1": "1"},
+		},
+	}
+	for _, tt := range tests {
+		where, params := tt.filter.where(quote)
+		if where != tt.wantWhere || !reflect.DeepEqual(params, tt.wantParams) {
+			t.Errorf("%s: where = %q, %v; want %q, %v", tt.name, where, params, tt.wantWhere, tt.wantParams)
+		}
+	}
+}
+
+func TestSelectQuery(t *testing.T) {
+	d, err := GetDialect("postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nameIsA := Filter{Rules: []FilterRule{{Column: "name", Op: OpEqual, Value: "a"}}}
+	tests := []struct {
+		name string
+		page Page
+		want string
+	}{
+		{
+			name: "first page by offset",
+			page: Page{Table: "t", Limit: 10},
+			want: `SELECT * FROM "t" LIMIT 10;`,
+		},
+		{
+			name: "sorted page by offset",
+			page: Page{Table: "t", OrderBy: []string{"name"}, Direction: "DESC", Limit: 10, Offset: 20},
+			want: `SELECT * FROM "t" ORDER BY "name" DESC LIMIT 10 OFFSET 20;`,
+		},
+		{
+			name: "next page by key",
+			page: Page{Table: "t", OrderBy: []string{"id"}, Direction: "ASC", Limit: 10, Key: []any{int64(5)}},
+			want: `SELECT * FROM "t" WHERE "id" > :key1 ORDER BY "id" ASC LIMIT 10;`,
+		},
+		{
+			name: "previous page by key",
+			page: Page{Table: "t", OrderBy: []string{"id"}, Direction: "ASC", Limit: 10, Key: []any{int64(5)}, Before: true},
+			want: `SELECT * FROM (SELECT * FROM "t" WHERE "id" < :key1 ORDER BY "id" DESC LIMIT 10) AS page ORDER BY "id" ASC;`,
+		},
+		{
+			name: "next page by composite key, descending",
+			page: Page{Table: "t", OrderBy: []string{"a", "b"}, Direction: "DESC", Limit: 10, Key: []any{1, 2}},
+			want: `SELECT * FROM "t" WHERE ("a", "b") < (:key1, :key2) ORDER BY "a" DESC, "b" DESC LIMIT 10;`,
+		},
+		{
+			name: "last page by key",
+			page: Page{Table: "t", OrderBy: []string{"id"}, Direction: "ASC", Limit: 10, Last: true},
+			want: `SELECT * FROM (SELECT * FROM "t" ORDER BY "id" DESC LIMIT 10) AS page ORDER BY "id" ASC;`,
+		},
+		{
+			name: "filtered page by key",
+			page: Page{Table: "t", OrderBy: []string{"id"}, Limit: 10, Key: []any{int64(5)}, Filter: nameIsA},
+			want: `SELECT * FROM "t" WHERE "name" = :filter1 AND "id" > :key1 ORDER BY "id" ASC LIMIT 10;`,
+		},
+		{
+			name: "filtered page by offset",
+			page: Page{Table: "t", Limit: 10, Offset: 10, Filter: nameIsA},
+			want: `SELECT * FROM "t" WHERE "name" = :filter1 LIMIT 10 OFFSET 10;`,
+		},
+	}
+	for _, tt := range tests {
+		if got := d.SelectQuery(tt.page); got != tt.want {
+			t.Errorf("%s: SelectQuery =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBrowsePages(t *testing.T) {
+	dbh := openTestDB(t)
+	ctx := context.Background()
+	if _, err := dbh.ExecContext(ctx, "INSERT INTO t VALUES (3, 'c'), (4, NULL), (5, 'a')"); err != nil {
+		t.Fatal(err)
+	}
+	d, _ := GetDialect("sqlite")
+
+	tests := []struct {
+		name string
+		page Page
+		want []int64
+	}{
+		{"keyset next", Page{Table: "t", OrderBy: []string{"id"}, Limit: 2, Key: []any{int64(2)}}, []int64{3, 4}},
+		{"keyset previous", Page{Table: "t", OrderBy: []string{"id"}, Limit: 2, Key: []any{int64(4)}, Before: true}, []int64{2, 3}},
+		{"keyset last", Page{Table: "t", OrderBy: []string{"id"}, Direction: "DESC", Limit: 2, Last: true}, []int64{2, 1}},
+		{"offset", Page{Table: "t", OrderBy: []string{"id"}, Limit: 2, Offset: 3}, []int64{4, 5}},
+		{"IN", Page{Table: "t", OrderBy: []string{"id"}, Filter: Filter{Rules: []FilterRule{{Column: "name", Op: OpIn, Value: "a,c"}}}}, []int64{1, 3, 5}},
+		{"BETWEEN", Page{Table: "t", OrderBy: []string{"id"}, Filter: Filter{Rules: []FilterRule{{Column: "id", Op: OpBetween, Value: "2", Value2: "4"}}}}, []int64{2, 3, 4}},
+		{"IS NULL or", Page{Table: "t", OrderBy: []string{"id"}, Filter: Filter{Any: true, Rules: []FilterRule{
+			{Column: "name", Op: OpIsNull}, {Column: "name", Op: OpEqual, Value: "b"},
+		}}}, []int64{2, 4}},
+		{"filtered keyset", Page{Table: "t", OrderBy: []string{"id"}, Limit: 1, Key: []any{int64(1)}, Filter: Filter{Rules: []FilterRule{{Column: "name", Op: OpEqual, Value: "a"}}}}, []int64{5}},
+	}
+	for _, tt := range tests {
+		query, args := BindParameters(d.SelectQuery(tt.page), d.Syntax(), tt.page.Params())
+		rows, err := dbh.QueryContext(ctx, query, args...)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []int64
+		for rows.Next() {
+			var id int64
+			var name any
+			if err := rows.Scan(&id, &name); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, id)
+		}
+		rows.Close()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ids = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax describes the rules of a dialect's SQL that matter for splitting
+// scripts, classifying statements and generating them
+type Syntax struct {
+	BackslashEscapes    bool // Backslash escapes in '...' and "..." strings (MySQL)
+	DoubleQuotedStrings bool // "..." is a string rather than an identifier (MySQL)
+	EscapeStrings       bool // E'...' strings with backslash escapes (PostgreSQL)
+	DollarQuotes        bool // $tag$...$tag$ strings (PostgreSQL)
+	Backticks           bool // `...` quotes identifiers (MySQL, SQLite)
+	Brackets            bool // [...] quotes identifiers (SQLite)
+	HashComments        bool // # starts a line comment (MySQL)
+	NestedComments      bool // /* ... */ comments nest (PostgreSQL)
+	ArraySlices         bool // arr[a:b] slices arrays, so a:b is no parameter (PostgreSQL)
+	Delimiter           bool // The client-side DELIMITER command (MySQL)
+	TriggerBodies       bool // Semicolons inside CREATE TRIGGER ... BEGIN ... END (SQLite)
+	TableStatusResults  bool // ANALYZE, CHECK, OPTIMIZE and REPAIR TABLE return a status grid (MySQL)
+	NumberedParams      bool // The driver takes $1 style parameters (PostgreSQL)
+	QuestionParams      bool // The driver takes ? parameters (MySQL, SQLite)
+	EmptyValuesList     bool // INSERT ... () VALUES () instead of DEFAULT VALUES (MySQL)
+}
+
+type tokenKind int
+
+const (
+	tokWord      tokenKind = iota // Keyword or bare identifier
+	tokQuoted                     // Quoted identifier
+	tokString                     // String literal, dollar-quoted included
+	tokNumber                     // Numeric literal
+	tokComment                    // -- line, # line or /* block */ comment
+	tokSpace                      // Whitespace
+	tokDelimiter                  // Statement delimiter
+	tokPunct                      // Any other single character
+)
+
+// token is a lexeme of a script. pos is its byte offset.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexer splits SQL into tokens. Unterminated strings and comments run to the
+// end of the input rather than failing; the server reports the error.
+type lexer struct {
+	src       string
+	pos       int
+	syntax    Syntax
+	delimiter string
+}
+
+func newLexer(src string, syntax Syntax) *lexer {
+	return &lexer{src: src, syntax: syntax, delimiter: ";"}
+}
+
+// next returns the next token, or false at the end of the input
+func (l *lexer) next() (token, bool) {
+	if l.pos >= len(l.src) {
+		return token{}, false
+	}
+	start := l.pos
+	rest := l.src[l.pos:]
+	c := rest[0]
+
+	kind := tokPunct
+	switch {
+	case strings.HasPrefix(rest, l.delimiter):
+		kind = tokDelimiter
+		l.pos += len(l.delimiter)
+	case isSpace(c):
+		kind = tokSpace
+		for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+			l.pos++
+		}
+	case strings.HasPrefix(rest, "--"), c == '#' && l.syntax.HashComments:
+		kind = tokComment
+		l.skipPast("\n")
+	case strings.HasPrefix(rest, "/*"):
+		kind = tokComment
+		l.blockComment()
+	case c == '\'':
+		kind = tokString
+		l.quoted('\'', l.syntax.BackslashEscapes)
+	case c == '"':
+		kind = tokQuoted
+		if l.syntax.DoubleQuotedStrings {
+			kind = tokString
+		}
+		l.quoted('"', l.syntax.BackslashEscapes)
+	case c == '`' && l.syntax.Backticks:
+		kind = tokQuoted
+		l.quoted('`', false)
+	case c == '[' && l.syntax.Brackets:
+		kind = tokQuoted
+		l.pos++
+		l.skipPast("]")
+	case (c == 'E' || c == 'e') && l.syntax.EscapeStrings && strings.HasPrefix(rest[1:], "'"):
+		kind = tokString
+		l.pos++
+		l.quoted('\'', true)
+	case c == '$' && l.syntax.DollarQuotes && dollarTag(rest) != "":
+		kind = tokString
+		tag := dollarTag(rest)
+		l.pos += len(tag)
+		l.skipPast(tag)
+	case c >= '0' && c <= '9', c == '.' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9':
+		kind = tokNumber
+		for l.pos < len(l.src) && (isWordByte(l.src[l.pos]) || l.src[l.pos] == '.') && !l.atDelimiter() {
+			l.pos++
+		}
+	case isWordStart(rest):
+		kind = tokWord
+		// Words may contain $, which a custom delimiter like $$ must end
+		for l.pos < len(l.src) && isWordRune(l.src[l.pos:]) && !l.atDelimiter() {
+			_, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			l.pos += size
+		}
+	default:
+		_, size := utf8.DecodeRuneInString(rest)
+		l.pos += size
+	}
+	return token{kind: kind, text: l.src[start:l.pos], pos: start}, true
+}
+
+func (l *lexer) atDelimiter() bool {
+	return strings.HasPrefix(l.src[l.pos:], l.delimiter)
+}
+
+// skipPast advances past the next occurrence of end, or to the end of input
+func (l *lexer) skipPast(end string) {
+	if i := strings.Index(l.src[l.pos:], end); i >= 0 {
+		l.pos += i + len(end)
+	} else {
+		l.pos = len(l.src)
+	}
+}
+
+// blockComment advances over a /* ... */ comment, counting nested comments
+// where the syntax has them
+func (l *lexer) blockComment() {
+	l.pos += 2
+	if !l.syntax.NestedComments {
+		l.skipPast("*/")
+		return
+	}
+	for depth := 1; l.pos < len(l.src); {
+		switch rest := l.src[l.pos:]; {
+		case strings.HasPrefix(rest, "/*"):
+			depth++
+			l.pos += 2
+		case strings.HasPrefix(rest, "*/"):
+			l.pos += 2
+			if depth--; depth == 0 {
+				return
+			}
+		default:
+			l.pos++
+		}
+	}
+}
+
+// quoted advances over a literal enclosed in q, where a doubled q stands for
+// itself and backslash, if enabled, escapes the next character
+func (l *lexer) quoted(q byte, backslash bool) {
+	l.pos++ // Opening quote
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == '\\' && backslash:
+			l.pos += 2
+		case c == q && l.pos+1 < len(l.src) && l.src[l.pos+1] == q:
+			l.pos += 2
+		case c == q:
+			l.pos++
+			return
+		default:
+			l.pos++
+		}
+	}
+	l.pos = len(l.src)
+}
+
+// dollarTag returns the opening $tag$ of a dollar-quoted string at the start
+// of s, or "" if there is none. $1 style parameters are not tags.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c >= '0' && c <= '9':
+			if i == 1 {
+				return ""
+			}
+		case !isWordByte(c):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isWordStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isWordRune(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Unlimited disables a timeout or the row limit in Limits
+const Unlimited = -1
+
+// minPoolSize covers the editor and metadata connections of a Session plus
+// one for cancelling queries
+const minPoolSize = 3
+
+// Limits are the timeouts and size limits of a connection. Timeouts are in
+// seconds. Zero fields are unset and take the value of a fallback.
+type Limits struct {
+	ConnectTimeout   int `json:"connect_timeout,omitempty"`
+	StatementTimeout int `json:"statement_timeout,omitempty"`
+	MetadataTimeout  int `json:"metadata_timeout,omitempty"` // Sidebar and table info lookups
+	MaxRows          int `json:"max_rows,omitempty"`         // Rows fetched per result
+	PoolSize         int `json:"pool_size,omitempty"`        // Maximum open connections
+}
+
+// DefaultLimits apply where neither the connection nor the global settings
+// set a value
+var DefaultLimits = Limits{
+	ConnectTimeout:   5,
+	StatementTimeout: 30,
+	MetadataTimeout:  10,
+	MaxRows:          Unlimited,
+	PoolSize:         5,
+}
+
+// Or returns l with its unset fields taken from fallback
+func (l Limits) Or(fallback Limits) Limits {
+	pick := func(v, f int) int {
+		if v == 0 {
+			return f
+		}
+		return v
+	}
+	return Limits{
+		ConnectTimeout:   pick(l.ConnectTimeout, fallback.ConnectTimeout),
+		StatementTimeout: pick(l.StatementTimeout, fallback.StatementTimeout),
+		MetadataTimeout:  pick(l.MetadataTimeout, fallback.MetadataTimeout),
+		MaxRows:          pick(l.MaxRows, fallback.MaxRows),
+		PoolSize:         pick(l.PoolSize, fallback.PoolSize),
+	}
+}
+
+// Timeout converts a timeout field to a duration, 0 meaning none
+func Timeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// WithTimeout is context.WithTimeout for a timeout field. Without a timeout
+// the context is only cancellable.
+func WithTimeout(parent context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if d := Timeout(seconds); d > 0 {
+		return context.WithTimeout(parent, d)
+	}
+	return context.WithCancel(parent)
+}
@@ -1,16 +1,48 @@
 package db
 
+import "github.com/pn/kymar/internal/ssh"
+
 // ConnParams holds database connection parameters
 type ConnParams struct {
-	DBType  string // "mysql" or "postgres"
-	Host    string
-	Port    int
-	User    string
-	Pass    string
-	DB      string
+	DBType string // "mysql", "postgres" or "sqlite"
+	Host   string
+	Port   int
+	User   string
+	Pass   string
+	DB     string
+	Socket string // Unix socket path; overrides Host/Port when set
+	File   string // Database file for file-based engines (SQLite)
+	// TLS settings. SSLMode is one of SSLModes; empty means disable.
+	SSLMode string
+	SSLCA   string // CA certificate file
+	SSLCert string // Client certificate file
+	SSLKey  string // Client key file
 	UseSSH  bool
 	SSHHost string
 	SSHPort int
 	SSHUser string
 	SSHPass string
+	// SSH key authentication. Key passphrases are prompted for, never stored.
+	SSHKeyFile  string
+	SSHUseAgent bool
+	// Jump hosts dialled in order before SSHHost, like OpenSSH's ProxyJump
+	SSHJumps []ssh.Hop
+	// Key of the passwords in the secret store. Saved connections keep Pass,
+	// SSHPass and jump host passwords there instead of in the config file.
+	SecretRef string
+}
+
+// SSHHops returns the full tunnel chain: the jump hosts followed by SSHHost
+func (p ConnParams) SSHHops() []ssh.Hop {
+	hops := append([]ssh.Hop(nil), p.SSHJumps...)
+	return append(hops, ssh.Hop{
+		Host: p.SSHHost,
+		Port: p.SSHPort,
+		Auth: ssh.Auth{
+			User:     p.SSHUser,
+			Password: p.SSHPass,
+			KeyFile:  p.SSHKeyFile,
+			UseAgent: p.SSHUseAgent,
+		},
+	})
 }
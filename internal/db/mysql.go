@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	mysql "github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	RegisterDialect(mysqlDialect{})
+}
+
+// mysqlDialect implements Dialect for MySQL and MariaDB
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string        { return "mysql" }
+func (mysqlDialect) DisplayName() string { return "MySQL" }
+func (mysqlDialect) DefaultPort() int    { return 3306 }
+func (mysqlDialect) DefaultUser() string { return "root" }
+func (mysqlDialect) DriverName() string  { return "mysql" }
+
+func (mysqlDialect) DefaultSocket() string { return "/tmp/mysql.sock" }
+
+// DSN builds a go-sql-driver DSN. Verified TLS modes refer to a config
+// registered by Connector under mysqlTLSKey. UPDATEs report the rows they
+// matched, like on the other engines, so saving edits can tell a row that
+// is gone from one that already held the new values.
+func (mysqlDialect) DSN(p ConnParams) string {
+	addr := fmt.Sprintf("tcp(%s:%d)", p.Host, p.Port)
+	if p.Socket != "" {
+		addr = fmt.Sprintf("unix(%s)", p.Socket)
+	}
+	dsn := fmt.Sprintf("%s:%s@%s/%s?parseTime=true&multiStatements=true&clientFoundRows=true",
+		p.User, p.Pass, addr, p.DB)
+
+	switch p.SSLMode {
+	case "", SSLDisable:
+	case SSLPrefer:
+		dsn += "&tls=preferred"
+	default:
+		dsn += "&tls=" + mysqlTLSKey(p)
+	}
+	return dsn
+}
+
+// mysqlTLSKey names the registered TLS config for p. It is derived from the
+// settings so reconnecting reuses the same registration.
+func mysqlTLSKey(p ConnParams) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s", p.SSLMode, p.SSLCA, p.SSLCert, p.SSLKey, p.Host)
+	return fmt.Sprintf("kymar-%x", h.Sum64())
+}
+
+// Connector gives each connection its own dial function, so SSH tunnels are
+// never shared through a globally registered network. The config is built
+// field by field rather than parsed from DSN, so credentials and database
+// names containing '@', '/' or '?' reach the server unchanged.
+func (mysqlDialect) Connector(p ConnParams, dial DialFunc) (driver.Connector, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = p.User
+	cfg.Passwd = p.Pass
+	cfg.DBName = p.DB
+	cfg.Net, cfg.Addr = "tcp", fmt.Sprintf("%s:%d", p.Host, p.Port)
+	if p.Socket != "" {
+		cfg.Net, cfg.Addr = "unix", p.Socket
+	}
+	cfg.ParseTime = true
+	cfg.MultiStatements = true
+	cfg.ClientFoundRows = true
+
+	switch p.SSLMode {
+	case "", SSLDisable:
+	case SSLPrefer:
+		cfg.TLSConfig = "preferred"
+	default:
+		tlsCfg, err := buildTLSConfig(p)
+		if err != nil {
+			return nil, err
+		}
+		key := mysqlTLSKey(p)
+		if err := mysql.RegisterTLSConfig(key, tlsCfg); err != nil {
+			return nil, err
+		}
+		cfg.TLSConfig = key
+	}
+
+	if dial != nil {
+		cfg.DialFunc = dial
+	}
+	return mysql.NewConnector(cfg)
+}
+
+func (mysqlDialect) Syntax() Syntax {
+	return Syntax{
+		BackslashEscapes:    true,
+		DoubleQuotedStrings: true,
+		Backticks:           true,
+		HashComments:        true,
+		Delimiter:           true,
+		TableStatusResults:  true,
+		QuestionParams:      true,
+		EmptyValuesList:     true,
+	}
+}
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) ListTables(ctx context.Context, q Queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SHOW TABLES")
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (mysqlDialect) ListDatabases(ctx context.Context, q Queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SHOW DATABASES")
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (d mysqlDialect) UseDatabase(ctx context.Context, q Queryer, name string) error {
+	_, err := q.ExecContext(ctx, "USE "+d.QuoteIdent(name))
+	return err
+}
+
+func (mysqlDialect) CurrentDatabase(ctx context.Context, q Queryer) (string, error) {
+	var name sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&name)
+	return name.String, err
+}
+
+func (mysqlDialect) BackendID(ctx context.Context, q Queryer) (int64, error) {
+	var id int64
+	err := q.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+func (mysqlDialect) CancelQuery(ctx context.Context, q Queryer, id int64) error {
+	// KILL QUERY ends the statement but keeps the connection and its session
+	_, err := q.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id))
+	return err
+}
+
+func (mysqlDialect) SetStatementTimeout(ctx context.Context, q Queryer, d time.Duration) error {
+	// Only read-only SELECTs are covered
+	_, err := q.ExecContext(ctx, fmt.Sprintf("SET SESSION max_execution_time = %d", d.Milliseconds()))
+	return err
+}
+
+func (mysqlDialect) PrimaryKey(ctx context.Context, q Queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE()
+		AND TABLE_NAME = ?
+		AND CONSTRAINT_NAME = 'PRIMARY'
+		ORDER BY ORDINAL_POSITION
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (mysqlDialect) TableInfo(ctx context.Context, q Queryer, table string) (TableInfo, error) {
+	// Use information_schema for more reliable, version-independent queries
+	row := q.QueryRowContext(ctx, `
+		SELECT
+			ENGINE,
+			TABLE_ROWS,
+			DATA_LENGTH + INDEX_LENGTH as TOTAL_SIZE,
+			TABLE_COLLATION,
+			AUTO_INCREMENT,
+			CREATE_TIME
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()
+		AND TABLE_NAME = ?
+	`, table)
+
+	var info TableInfo
+	var collation sql.NullString
+	if err := row.Scan(&info.Engine, &info.Rows, &info.Size, &collation, &info.AutoIncrement, &info.Created); err != nil {
+		return info, err
+	}
+
+	// Views report no size; show 0 B rather than hiding it
+	info.Size.Valid = true
+	info.Encoding = sql.NullString{String: "unknown", Valid: true}
+	if collation.Valid && collation.String != "" {
+		info.Encoding.String = collation.String
+	}
+	return info, nil
+}
+
+func (d mysqlDialect) SelectQuery(p Page) string {
+	return selectQuery(d, p)
+}
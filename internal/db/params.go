@@ -0,0 +1,228 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParamType is the type hint of a query parameter
+type ParamType string
+
+const (
+	ParamString ParamType = "string"
+	ParamNumber ParamType = "number"
+	ParamNull   ParamType = "null"
+	ParamDate   ParamType = "date"
+)
+
+// ParamTypes lists the parameter types in the order the UI offers them
+var ParamTypes = []ParamType{ParamString, ParamNumber, ParamNull, ParamDate}
+
+// ParamValue is a value entered for a query parameter
+type ParamValue struct {
+	Type ParamType `json:"type"`
+	Text string    `json:"text,omitempty"`
+}
+
+// dateLayouts are the accepted formats of date parameters
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339}
+
+// Value converts v to the value bound to the driver. Dates are validated
+// and bound as text in a canonical format, which every dialect compares
+// correctly with its date columns.
+func (v ParamValue) Value() (any, error) {
+	switch v.Type {
+	case ParamNull:
+		return nil, nil
+	case ParamNumber:
+		text := strings.TrimSpace(v.Text)
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("%q is not a number", v.Text)
+	case ParamDate:
+		text := strings.TrimSpace(v.Text)
+		for i, layout := range dateLayouts {
+			t, err := time.Parse(layout, text)
+			if err != nil {
+				continue
+			}
+			if i == 0 {
+				return t.Format("2006-01-02"), nil
+			}
+			return t.Format("2006-01-02 15:04:05"), nil
+		}
+		return nil, fmt.Errorf("%q is not a date, use YYYY-MM-DD or YYYY-MM-DD HH:MM:SS", v.Text)
+	}
+	return v.Text, nil
+}
+
+// param is a placeholder in a statement. Positional ? placeholders are
+// named ?1, ?2, ... by their position in the statement.
+type param struct {
+	name       string
+	start, end int
+}
+
+// scanParams finds the placeholders of stmt: :name in every dialect, plus
+// $1 or ? where the syntax uses them. Placeholders inside strings, quoted
+// identifiers and comments are ignored, and so are :: casts, := and, where
+// arrays are sliced, the bounds of arr[a:b].
+func scanParams(stmt string, syntax Syntax) []param {
+	var toks []token
+	l := newLexer(stmt, syntax)
+	for {
+		tok, ok := l.next()
+		if !ok {
+			break
+		}
+		toks = append(toks, tok)
+	}
+	// adjacent returns the token right after toks[i] if it has kind
+	adjacent := func(i int, kind tokenKind) (token, bool) {
+		if i+1 < len(toks) && toks[i+1].kind == kind && toks[i+1].pos == toks[i].pos+1 {
+			return toks[i+1], true
+		}
+		return token{}, false
+	}
+	// slice reports whether toks[i] separates the bounds of an array slice,
+	// right after an identifier, a number or a closing bracket
+	slice := func(i int) bool {
+		if !syntax.ArraySlices || i == 0 {
+			return false
+		}
+		prev := toks[i-1]
+		if prev.pos+len(prev.text) != toks[i].pos {
+			return false
+		}
+		return prev.kind == tokWord || prev.kind == tokNumber || prev.text == "]" || prev.text == ")"
+	}
+
+	var params []param
+	questions := 0
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.kind != tokPunct {
+			continue
+		}
+		switch {
+		case tok.text == ":" && (i == 0 || toks[i-1].text != ":") && !slice(i):
+			if word, ok := adjacent(i, tokWord); ok {
+				params = append(params, param{":" + word.text, tok.pos, word.pos + len(word.text)})
+				i++
+			}
+		case tok.text == "$" && syntax.NumberedParams:
+			if num, ok := adjacent(i, tokNumber); ok {
+				params = append(params, param{"$" + num.text, tok.pos, num.pos + len(num.text)})
+				i++
+			}
+		case tok.text == "?" && syntax.QuestionParams:
+			if num, ok := adjacent(i, tokNumber); ok {
+				params = append(params, param{"?" + num.text, tok.pos, num.pos + len(num.text)})
+				i++
+				continue
+			}
+			questions++
+			params = append(params, param{"?" + strconv.Itoa(questions), tok.pos, tok.pos + 1})
+		}
+	}
+	return params
+}
+
+// Parameters returns the distinct placeholder names of stmt in order of
+// appearance
+func Parameters(stmt string, syntax Syntax) []string {
+	var names []string
+	for _, p := range scanParams(stmt, syntax) {
+		if !slices.Contains(names, p.name) {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
+
+// positional reports whether name is a positional placeholder, ?, ?N or
+// $N, whose value belongs to its statement only
+func positional(name string) bool {
+	return name[0] == '?' || name[0] == '$'
+}
+
+// scriptParam returns the name a placeholder of statement i takes in a
+// script of n statements. Named placeholders are shared by the whole script;
+// positional ones are qualified with the number of their statement.
+func scriptParam(name string, i, n int) string {
+	if n > 1 && positional(name) {
+		return fmt.Sprintf("%s (statement %d)", name, i+1)
+	}
+	return name
+}
+
+// ScriptParameters returns the distinct placeholder names of stmts in order
+// of appearance. With several statements, positional placeholders are
+// qualified by statement, as in "?1 (statement 2)".
+func ScriptParameters(stmts []Statement, syntax Syntax) []string {
+	var names []string
+	for i, st := range stmts {
+		for _, name := range Parameters(st.Text, syntax) {
+			name = scriptParam(name, i, len(stmts))
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// StatementValues returns the values of the placeholders of statement i of
+// stmts, given values by ScriptParameters name, by the names BindParameters
+// looks up
+func StatementValues(stmts []Statement, i int, syntax Syntax, values map[string]any) map[string]any {
+	if len(stmts) == 1 {
+		return values
+	}
+	own := map[string]any{}
+	for _, name := range Parameters(stmts[i].Text, syntax) {
+		if v, ok := values[scriptParam(name, i, len(stmts))]; ok {
+			own[name] = v
+		}
+	}
+	return own
+}
+
+// BindParameters rewrites the placeholders of stmt into the driver's own, $n
+// or ?, and returns the arguments to pass along with the statement
+func BindParameters(stmt string, syntax Syntax, values map[string]any) (string, []any) {
+	params := scanParams(stmt, syntax)
+	if len(params) == 0 {
+		return stmt, nil
+	}
+
+	var b strings.Builder
+	var args []any
+	var numbered []string // Distinct names in order, for $n
+	last := 0
+	for _, p := range params {
+		b.WriteString(stmt[last:p.start])
+		last = p.end
+		if !syntax.NumberedParams {
+			b.WriteString("?")
+			args = append(args, values[p.name])
+			continue
+		}
+		n := slices.Index(numbered, p.name)
+		if n < 0 {
+			numbered = append(numbered, p.name)
+			args = append(args, values[p.name])
+			n = len(numbered) - 1
+		}
+		fmt.Fprintf(&b, "$%d", n+1)
+	}
+	b.WriteString(stmt[last:])
+	return b.String(), args
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParameters(t *testing.T) {
+	tests := []struct {
+		dialect string
+		stmt    string
+		want    []string
+	}{
+		{"mysql", "SELECT * FROM t WHERE a = :a AND b IN (:b, :a)", []string{":a", ":b"}},
+		{"mysql", "SELECT * FROM t WHERE a = ? AND b = ?", []string{"?1", "?2"}},
+		{"sqlite", "SELECT ?2, ?1, ?2", []string{"?2", "?1"}},
+		{"mysql", "SELECT ':a', \"?\", `:b` -- :c ?\n/* :d */", nil},
+		{"mysql", "SET @x := 1", nil},
+		{"postgres", "SELECT $1, $2, $1", []string{"$1", "$2"}},
+		{"postgres", "SELECT ?, a::text, :name", []string{":name"}},
+		{"postgres", "SELECT $$ :a $$, E'\\' :b'", nil},
+		{"postgres", "SELECT arr[a:b], arr[1:n], arr[2][1:n], arr[f(x):n]", nil},
+		{"postgres", "SELECT arr[:b] FROM t WHERE x = (:x)", []string{":b", ":x"}},
+		{"mysql", "SELECT a FROM t WHERE b=:b", []string{":b"}},
+		{"mysql", "SELECT col:b", []string{":b"}},
+	}
+	for _, tt := range tests {
+		if got := Parameters(tt.stmt, syntaxOf(t, tt.dialect)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Parameters(%q) = %q, want %q", tt.dialect, tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestBindParameters(t *testing.T) {
+	tests := []struct {
+		dialect   string
+		stmt      string
+		values    map[string]any
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			dialect:   "mysql",
+			stmt:      "SELECT * FROM t WHERE a = :a AND b = :b OR c = :a",
+			values:    map[string]any{":a": 1, ":b": "x"},
+			wantQuery: "SELECT * FROM t WHERE a = ? AND b = ? OR c = ?",
+			wantArgs:  []any{1, "x", 1},
+		},
+		{
+			dialect:   "sqlite",
+			stmt:      "UPDATE t SET a = ? WHERE id = ?",
+			values:    map[string]any{"?1": "x", "?2": nil},
+			wantQuery: "UPDATE t SET a = ? WHERE id = ?",
+			wantArgs:  []any{"x", nil},
+		},
+		{
+			dialect:   "postgres",
+			stmt:      "SELECT :a, :b::int, :a, $1",
+			values:    map[string]any{":a": 1, ":b": "2", "$1": 3},
+			wantQuery: "SELECT $1, $2::int, $1, $3",
+			wantArgs:  []any{1, "2", 3},
+		},
+		{
+			dialect:   "postgres",
+			stmt:      "SELECT arr[a:b] FROM t",
+			wantQuery: "SELECT arr[a:b] FROM t",
+		},
+	}
+	for _, tt := range tests {
+		query, args := BindParameters(tt.stmt, syntaxOf(t, tt.dialect), tt.values)
+		if query != tt.wantQuery || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%s: BindParameters(%q) = %q, %v; want %q, %v", tt.dialect, tt.stmt, query, args, tt.wantQuery, tt.wantArgs)
+		}
+	}
+}
+
+func TestScriptParameters(t *testing.T) {
+	syntax := syntaxOf(t, "sqlite")
+	stmts := SplitScript("SELECT ?, :a; SELECT ?, ?, :a", syntax)
+
+	names := ScriptParameters(stmts, syntax)
+	want := []string{"?1 (statement 1)", ":a", "?1 (statement 2)", "?2 (statement 2)"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("ScriptParameters = %q, want %q", names, want)
+	}
+
+	values := map[string]any{"?1 (statement 1)": "x", ":a": "a", "?1 (statement 2)": "y", "?2 (statement 2)": "z"}
+	wantArgs := [][]any{{"x", "a"}, {"y", "z", "a"}}
+	for i, st := range stmts {
+		_, args := BindParameters(st.Text, syntax, StatementValues(stmts, i, syntax, values))
+		if !reflect.DeepEqual(args, wantArgs[i]) {
+			t.Errorf("statement %d: args = %v, want %v", i+1, args, wantArgs[i])
+		}
+	}
+
+	single := SplitScript("SELECT ?", syntax)
+	if names := ScriptParameters(single, syntax); !slices.Equal(names, []string{"?1"}) {
+		t.Errorf("ScriptParameters of one statement = %q, want [?1]", names)
+	}
+}
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq" // PostgreSQL driver
+)
+
+func init() {
+	RegisterDialect(postgresDialect{})
+}
+
+// postgresDialect implements Dialect for PostgreSQL
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string        { return "postgres" }
+func (postgresDialect) DisplayName() string { return "PostgreSQL" }
+func (postgresDialect) DefaultPort() int    { return 5432 }
+func (postgresDialect) DefaultUser() string { return "postgres" }
+func (postgresDialect) DriverName() string  { return "postgres" }
+
+func (postgresDialect) DefaultSocket() string { return "/var/run/postgresql" }
+
+// DSN builds a lib/pq keyword/value connection string. lib/pq has no
+// "prefer" mode, so it maps to "require"; Connector adds the plain-text
+// fallback.
+func (d postgresDialect) DSN(p ConnParams) string {
+	host, port := p.Host, p.Port
+	if p.Socket != "" {
+		host, port = pqSocket(p.Socket, p.Port)
+	}
+	if port == 0 {
+		port = d.DefaultPort()
+	}
+
+	mode := p.SSLMode
+	switch mode {
+	case "":
+		mode = SSLDisable
+	case SSLPrefer:
+		mode = SSLRequire
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s sslmode=%s",
+		pqValue(host), port, pqValue(p.User), pqValue(p.Pass), mode)
+	if p.DB != "" {
+		dsn += " dbname=" + pqValue(p.DB)
+	}
+	if mode != SSLDisable {
+		if p.SSLCA != "" {
+			dsn += " sslrootcert=" + pqValue(p.SSLCA)
+		}
+		if p.SSLCert != "" {
+			dsn += " sslcert=" + pqValue(p.SSLCert)
+		}
+		if p.SSLKey != "" {
+			dsn += " sslkey=" + pqValue(p.SSLKey)
+		}
+	}
+	return dsn
+}
+
+// Connector dials through dial when given, so SSH tunnels reach the server
+// under its real host name and verify-full works through them
+func (d postgresDialect) Connector(p ConnParams, dial DialFunc) (driver.Connector, error) {
+	open := func(p ConnParams) (driver.Connector, error) {
+		c, err := pq.NewConnector(d.DSN(p))
+		if err != nil {
+			return nil, err
+		}
+		if dial != nil {
+			c.Dialer(pqDialer(dial))
+		}
+		return c, nil
+	}
+
+	if p.SSLMode != SSLPrefer {
+		return open(p)
+	}
+
+	secure, err := open(p)
+	if err != nil {
+		return nil, err
+	}
+	p.SSLMode = SSLDisable
+	plain, err := open(p)
+	if err != nil {
+		return nil, err
+	}
+	return preferConnector{secure: secure, plain: plain}, nil
+}
+
+// preferConnector implements sslmode=prefer: TLS when the server offers it,
+// plain text otherwise
+type preferConnector struct {
+	secure, plain driver.Connector
+}
+
+func (c preferConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.secure.Connect(ctx)
+	if errors.Is(err, pq.ErrSSLNotSupported) {
+		return c.plain.Connect(ctx)
+	}
+	return conn, err
+}
+
+func (c preferConnector) Driver() driver.Driver {
+	return c.secure.Driver()
+}
+
+// pqDialer adapts a DialFunc to lib/pq's Dialer and DialerContext interfaces
+type pqDialer DialFunc
+
+func (d pqDialer) Dial(network, addr string) (net.Conn, error) {
+	return d(context.Background(), network, addr)
+}
+
+func (d pqDialer) DialTimeout(network, addr string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d(ctx, network, addr)
+}
+
+func (d pqDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d(ctx, network, addr)
+}
+
+// pqSocket splits a socket path into the directory lib/pq expects as host and
+// the port encoded in the socket file name. Both "/var/run/postgresql" and
+// "/var/run/postgresql/.s.PGSQL.5433" are accepted.
+func pqSocket(path string, port int) (string, int) {
+	dir, file := filepath.Split(path)
+	if n, ok := strings.CutPrefix(file, ".s.PGSQL."); ok {
+		if p, err := strconv.Atoi(n); err == nil {
+			return filepath.Clean(dir), p
+		}
+	}
+	return path, port
+}
+
+// pqValue quotes a keyword/value DSN value when it contains spaces or quotes
+func pqValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+func (postgresDialect) Syntax() Syntax {
+	return Syntax{EscapeStrings: true, DollarQuotes: true, NestedComments: true, ArraySlices: true, NumberedParams: true}
+}
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) ListTables(ctx context.Context, q Queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_type = 'BASE TABLE'")
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (postgresDialect) BackendID(ctx context.Context, q Queryer) (int64, error) {
+	var pid int64
+	err := q.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid)
+	return pid, err
+}
+
+func (postgresDialect) CancelQuery(ctx context.Context, q Queryer, id int64) error {
+	_, err := q.ExecContext(ctx, "SELECT pg_cancel_backend($1)", id)
+	return err
+}
+
+func (postgresDialect) SetStatementTimeout(ctx context.Context, q Queryer, d time.Duration) error {
+	_, err := q.ExecContext(ctx, fmt.Sprintf("SET statement_timeout = %d", d.Milliseconds()))
+	return err
+}
+
+func (postgresDialect) PrimaryKey(ctx context.Context, q Queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT a.attname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = quote_ident($1)::regclass AND i.indisprimary
+		ORDER BY array_position(i.indkey::int2[], a.attnum)
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (d postgresDialect) TableInfo(ctx context.Context, q Queryer, table string) (TableInfo, error) {
+	row := q.QueryRowContext(ctx, `
+		SELECT
+			pg_size_pretty(pg_total_relation_size(quote_ident($1)::regclass)) as size,
+			(SELECT count(*) FROM `+d.QuoteIdent(table)+`) as row_count,
+			obj_description(quote_ident($1)::regclass) as comment
+	`, table)
+
+	var info TableInfo
+	err := row.Scan(&info.SizePretty, &info.Rows, &info.Comment)
+	return info, err
+}
+
+func (d postgresDialect) SelectQuery(p Page) string {
+	return selectQuery(d, p)
+}
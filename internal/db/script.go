@@ -0,0 +1,122 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Statement is one statement of a script. Start and End are the byte offsets
+// of Text in the script.
+type Statement struct {
+	Text       string
+	Start, End int
+}
+
+// delimiterCommand matches the mysql client's DELIMITER command
+var delimiterCommand = regexp.MustCompile(`(?i)^delimiter[ \t]+(\S+)[^\n]*`)
+
+// SplitScript splits a script into statements. Delimiters inside strings,
+// quoted identifiers and comments are ignored, and so are statements made of
+// nothing but comments. DELIMITER commands are handled when the syntax allows
+// them and are not returned.
+func SplitScript(script string, syntax Syntax) []Statement {
+	var stmts []Statement
+	l := newLexer(script, syntax)
+
+	start := -1 // Offset of the first meaningful token of the statement
+	end := 0    // Offset just past its last meaningful token
+	var words []string
+	depth := 0 // BEGIN/CASE nesting inside a trigger body
+
+	emit := func() {
+		if start >= 0 {
+			stmts = append(stmts, Statement{Text: script[start:end], Start: start, End: end})
+		}
+		start, words, depth = -1, nil, 0
+	}
+
+	for {
+		if start < 0 && syntax.Delimiter {
+			// A DELIMITER command may only open a statement
+			rest := script[l.pos:]
+			trimmed := strings.TrimLeft(rest, " \t\r\n")
+			if m := delimiterCommand.FindStringSubmatch(trimmed); m != nil {
+				l.delimiter = m[1]
+				l.pos += len(rest) - len(trimmed) + len(m[0])
+				continue
+			}
+		}
+
+		tok, ok := l.next()
+		if !ok {
+			break
+		}
+		switch tok.kind {
+		case tokSpace, tokComment:
+			continue
+		case tokDelimiter:
+			if depth == 0 {
+				emit()
+				continue
+			}
+		case tokWord:
+			if syntax.TriggerBodies {
+				words = append(words, strings.ToUpper(tok.text))
+				depth = triggerDepth(words, depth)
+			}
+		}
+		if start < 0 {
+			start = tok.pos
+		}
+		end = tok.pos + len(tok.text)
+	}
+	emit()
+	return stmts
+}
+
+// triggerDepth tracks BEGIN ... END nesting in CREATE TRIGGER statements,
+// whose bodies hold semicolons. CASE ... END counts as nesting too.
+func triggerDepth(words []string, depth int) int {
+	if !isCreateTrigger(words) {
+		return 0
+	}
+	switch words[len(words)-1] {
+	case "BEGIN", "CASE":
+		return depth + 1
+	case "END":
+		if depth > 0 {
+			return depth - 1
+		}
+	}
+	return depth
+}
+
+// isCreateTrigger reports whether words open CREATE [TEMP] TRIGGER
+func isCreateTrigger(words []string) bool {
+	if len(words) < 2 || words[0] != "CREATE" {
+		return false
+	}
+	if words[1] == "TEMP" || words[1] == "TEMPORARY" {
+		return len(words) > 2 && words[2] == "TRIGGER"
+	}
+	return words[1] == "TRIGGER"
+}
+
+// StatementAt returns the statement of stmts at offset, such as a cursor
+// position. Between two statements it picks the one before, so a cursor
+// right after a delimiter still selects the statement just typed.
+func StatementAt(stmts []Statement, offset int) (Statement, bool) {
+	if len(stmts) == 0 {
+		return Statement{}, false
+	}
+	for i, st := range stmts {
+		if offset > st.End {
+			continue
+		}
+		if offset < st.Start && i > 0 {
+			return stmts[i-1], true
+		}
+		return st, true
+	}
+	return stmts[len(stmts)-1], true
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		script  string
+		want    []string
+	}{
+		{
+			name:    "statements and comments",
+			dialect: "sqlite",
+			script:  "SELECT 1; -- only a comment;\n/* another; */ SELECT 2;;",
+			want:    []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:    "delimiters in strings and identifiers",
+			dialect: "sqlite",
+			script:  `SELECT 'a;b', "c;d", [e;f], ` + "`g;h`" + `; SELECT 2`,
+			want:    []string{`SELECT 'a;b', "c;d", [e;f], ` + "`g;h`", "SELECT 2"},
+		},
+		{
+			name:    "sqlite trigger body",
+			dialect: "sqlite",
+			script:  "CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE b SET n = CASE WHEN 1 THEN 2 END; DELETE FROM c; END; SELECT 1",
+			want:    []string{"CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE b SET n = CASE WHEN 1 THEN 2 END; DELETE FROM c; END", "SELECT 1"},
+		},
+		{
+			name:    "mysql DELIMITER",
+			dialect: "mysql",
+			script:  "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END//\nDELIMITER ;\nCALL p();",
+			want:    []string{"CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END", "CALL p()"},
+		},
+		{
+			name:    "mysql $$ delimiter",
+			dialect: "mysql",
+			script:  "DELIMITER $$\nCREATE FUNCTION f() RETURNS INT RETURN 1$$\nDELIMITER ;\nSELECT f()",
+			want:    []string{"CREATE FUNCTION f() RETURNS INT RETURN 1", "SELECT f()"},
+		},
+		{
+			name:    "mysql backslash escapes and hash comments",
+			dialect: "mysql",
+			script:  `SELECT 'it\'s;'; # comment;` + "\nSELECT \"x;\"",
+			want:    []string{`SELECT 'it\'s;'`, `SELECT "x;"`},
+		},
+		{
+			name:    "DELIMITER is a word on postgres",
+			dialect: "postgres",
+			script:  "DELIMITER //\nSELECT 1",
+			want:    []string{"DELIMITER //\nSELECT 1"},
+		},
+		{
+			name:    "postgres dollar quotes",
+			dialect: "postgres",
+			script:  "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; SELECT $tag$ a; $$ b; $tag$, $1",
+			want:    []string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", "SELECT $tag$ a; $$ b; $tag$, $1"},
+		},
+		{
+			name:    "postgres escape strings",
+			dialect: "postgres",
+			script:  `SELECT E'it\'s;', 'a\'; SELECT 2`,
+			want:    []string{`SELECT E'it\'s;', 'a\'`, "SELECT 2"},
+		},
+		{
+			name:    "postgres nested comments",
+			dialect: "postgres",
+			script:  "SELECT 1 /* a /* b */ ; */; SELECT 2",
+			want:    []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:    "comments don't nest on mysql",
+			dialect: "mysql",
+			script:  "SELECT 1 /* a /* b */ ; */; SELECT 2",
+			want:    []string{"SELECT 1", "*/", "SELECT 2"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, st := range SplitScript(tt.script, syntaxOf(t, tt.dialect)) {
+			got = append(got, st.Text)
+			if tt.script[st.Start:st.End] != st.Text {
+				t.Errorf("%s: statement %q has offsets %d-%d", tt.name, st.Text, st.Start, st.End)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: SplitScript = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	script := "SELECT 1;  SELECT 2"
+	stmts := SplitScript(script, syntaxOf(t, "sqlite"))
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{0, "SELECT 1"},
+		{9, "SELECT 1"},  // Right after the delimiter
+		{10, "SELECT 1"}, // Between the statements
+		{11, "SELECT 2"},
+		{len(script), "SELECT 2"},
+	}
+	for _, tt := range tests {
+		if got, ok := StatementAt(stmts, tt.offset); !ok || got.Text != tt.want {
+			t.Errorf("StatementAt(%d) = %q, want %q", tt.offset, got.Text, tt.want)
+		}
+	}
+}
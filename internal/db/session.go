@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// Session pins an editor to a single connection of the pool, so USE, SET,
+// transactions and temporary tables carry over from one query to the next.
+// Sidebar and table metadata lookups run on a second pinned connection and
+// never disturb the editor's state.
+type Session struct {
+	db      *sql.DB
+	dialect Dialect
+	conn    *sql.Conn // Editor queries
+	meta    *sql.Conn // Metadata queries
+	tx      *sql.Tx   // Open manual-commit transaction on conn, if any
+	// Server id of conn for QueryCanceller dialects; 0 if unknown
+	backendID int64
+	// Server-side statement timeout for StatementTimeouter dialects
+	statementTimeout time.Duration
+	// Database selected since the session opened, or "" for the DSN's.
+	// Reopened connections are switched back to it.
+	database string
+}
+
+// OpenSession takes the editor and metadata connections from dbh. Dialects
+// implementing StatementTimeouter have the server enforce statementTimeout
+// on editor queries; 0 means none.
+func OpenSession(ctx context.Context, dbh *sql.DB, dialect Dialect, statementTimeout time.Duration) (*Session, error) {
+	conn, err := dbh.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := dbh.Conn(ctx)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	s := &Session{db: dbh, dialect: dialect, conn: conn, meta: meta, statementTimeout: statementTimeout}
+	s.setup(ctx)
+	return s, nil
+}
+
+// setup prepares a new editor connection. Both steps are best effort: the
+// session works without them, only less well.
+func (s *Session) setup(ctx context.Context) {
+	s.captureBackendID(ctx)
+	if t, ok := s.dialect.(StatementTimeouter); ok && s.statementTimeout > 0 {
+		// MariaDB and old MySQL versions lack max_execution_time
+		_ = t.SetStatementTimeout(ctx, s.conn, s.statementTimeout)
+	}
+}
+
+// captureBackendID remembers the server id of the editor connection. Without
+// it, CancelQuery can only give up on the query client side.
+func (s *Session) captureBackendID(ctx context.Context) {
+	s.backendID = 0
+	if c, ok := s.dialect.(QueryCanceller); ok {
+		s.backendID, _ = c.BackendID(ctx, s.conn)
+	}
+}
+
+// CancelQuery asks the server to stop the statement running on the editor
+// connection. It goes through a third connection from the pool, since the
+// editor and metadata connections may be busy. It reports false when the
+// dialect has no way to do so.
+func (s *Session) CancelQuery(ctx context.Context) (bool, error) {
+	c, ok := s.dialect.(QueryCanceller)
+	if !ok || s.backendID == 0 {
+		return false, nil
+	}
+	return true, c.CancelQuery(ctx, s.db, s.backendID)
+}
+
+// DB returns the pool the session was opened from
+func (s *Session) DB() *sql.DB { return s.db }
+
+// Conn returns the connection editor queries run on
+func (s *Session) Conn() *sql.Conn { return s.conn }
+
+// Queryer returns what editor queries run on: the open transaction, or the
+// editor connection outside one
+func (s *Session) Queryer() Queryer {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.conn
+}
+
+// Begin starts a manual-commit transaction on the editor connection. Editor
+// queries run inside it until Commit or Rollback.
+func (s *Session) Begin() error {
+	if s.tx != nil {
+		return errors.New("a transaction is already open")
+	}
+	// Not bound to a timeout: database/sql rolls the transaction back when
+	// its context ends
+	tx, err := s.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	s.tx = tx
+	return nil
+}
+
+// InTx reports whether a manual-commit transaction is open
+func (s *Session) InTx() bool { return s.tx != nil }
+
+// Commit commits the open transaction
+func (s *Session) Commit() error {
+	if s.tx == nil {
+		return sql.ErrTxDone
+	}
+	tx := s.tx
+	s.tx = nil
+	return tx.Commit()
+}
+
+// Rollback rolls the open transaction back
+func (s *Session) Rollback() error {
+	if s.tx == nil {
+		return sql.ErrTxDone
+	}
+	tx := s.tx
+	s.tx = nil
+	return tx.Rollback()
+}
+
+// Apply runs changes in a single transaction on the editor connection. In
+// manual-commit mode they join the open transaction, inside a savepoint, and
+// are left for the user to commit; otherwise they are committed. If any
+// fails, all of them are rolled back.
+func (s *Session) Apply(ctx context.Context, changes []Change) error {
+	if s.tx != nil {
+		if _, err := s.tx.ExecContext(ctx, "SAVEPOINT kymar_apply"); err != nil {
+			return err
+		}
+		for _, c := range changes {
+			if err := c.exec(ctx, s.tx); err != nil {
+				_, _ = s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT kymar_apply")
+				return err
+			}
+		}
+		_, err := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT kymar_apply")
+		return err
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := c.exec(ctx, tx); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Meta returns the connection for metadata lookups
+func (s *Session) Meta() *sql.Conn { return s.meta }
+
+// UseDatabase switches both connections to database name. The dialect must
+// implement DatabaseSwitcher.
+func (s *Session) UseDatabase(ctx context.Context, name string) error {
+	switcher, ok := s.dialect.(DatabaseSwitcher)
+	if !ok {
+		return errors.New(s.dialect.DisplayName() + " cannot switch databases")
+	}
+	if err := switcher.UseDatabase(ctx, s.conn, name); err != nil {
+		return err
+	}
+	if err := switcher.UseDatabase(ctx, s.meta, name); err != nil {
+		return err
+	}
+	s.database = name
+	return nil
+}
+
+// SyncDatabase makes the metadata connection follow a database switch made
+// by an editor query and returns the database now selected. It is a no-op
+// for dialects without DatabaseSwitcher.
+func (s *Session) SyncDatabase(ctx context.Context) (string, error) {
+	switcher, ok := s.dialect.(DatabaseSwitcher)
+	if !ok {
+		return "", nil
+	}
+	name, err := switcher.CurrentDatabase(ctx, s.conn)
+	if err != nil || name == "" || name == s.database {
+		return name, err
+	}
+	if err := switcher.UseDatabase(ctx, s.meta, name); err != nil {
+		return "", err
+	}
+	s.database = name
+	return name, nil
+}
+
+// ConnLost reports whether err means a pinned connection is gone, after which
+// every further query on it fails until the session is reopened
+func ConnLost(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone)
+}
+
+// Reopen replaces connections that no longer answer with fresh ones from the
+// pool and reports whether the editor connection was among them. Its session
+// state is lost then, apart from the selected database, and so is an open
+// transaction.
+func (s *Session) Reopen(ctx context.Context) (bool, error) {
+	if _, err := s.reopen(ctx, &s.meta); err != nil {
+		return false, err
+	}
+	reset, err := s.reopen(ctx, &s.conn)
+	if reset {
+		if s.tx != nil {
+			_ = s.tx.Rollback()
+			s.tx = nil
+		}
+		if err == nil {
+			s.setup(ctx)
+		}
+	}
+	return reset, err
+}
+
+// reopen replaces *c if it doesn't answer a ping
+func (s *Session) reopen(ctx context.Context, c **sql.Conn) (bool, error) {
+	if (*c).PingContext(ctx) == nil {
+		return false, nil
+	}
+	_ = (*c).Close()
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return true, err
+	}
+	*c = conn
+	if s.database != "" {
+		return true, s.dialect.(DatabaseSwitcher).UseDatabase(ctx, conn, s.database)
+	}
+	return true, nil
+}
+
+// Close rolls back an open transaction and releases both connections
+func (s *Session) Close() error {
+	var err error
+	if s.tx != nil {
+		err = s.Rollback()
+	}
+	return errors.Join(err, s.conn.Close(), s.meta.Close())
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+// openTestSession opens a session on the database of openTestDB
+func openTestSession(t *testing.T) *Session {
+	t.Helper()
+	dbh := openTestDB(t)
+
+	d, err := GetDialect("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenSession(context.Background(), dbh, d, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// names returns the names in t by id
+func names(t *testing.T, s *Session) string {
+	t.Helper()
+	var got string
+	err := s.Conn().QueryRowContext(context.Background(), "SELECT group_concat(name, ',') FROM (SELECT name FROM t ORDER BY id)").Scan(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	d, _ := GetDialect("sqlite")
+
+	tests := []struct {
+		name    string
+		changes []Change
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "update and insert",
+			changes: []Change{
+				UpdateRow(d, "t", []string{"name"}, []any{"x"}, []string{"id"}, []any{int64(1)}),
+				InsertRow(d, "t", []string{"id", "name"}, []any{int64(3), "c"}),
+			},
+			want: "x,b,c",
+		},
+		{
+			name: "update of a missing row rolls back",
+			changes: []Change{
+				UpdateRow(d, "t", []string{"name"}, []any{"x"}, []string{"id"}, []any{int64(1)}),
+				UpdateRow(d, "t", []string{"name"}, []any{"y"}, []string{"id"}, []any{int64(9)}),
+			},
+			want:    "a,b",
+			wantErr: true,
+		},
+		{
+			name: "delete of a missing row rolls back",
+			changes: []Change{
+				DeleteRow(d, "t", []string{"id"}, []any{int64(2)}),
+				DeleteRow(d, "t", []string{"id"}, []any{int64(9)}),
+			},
+			want:    "a,b",
+			wantErr: true,
+		},
+		{
+			name: "failing statement rolls back",
+			changes: []Change{
+				DeleteRow(d, "t", []string{"id"}, []any{int64(2)}),
+				InsertRow(d, "t", []string{"id", "name"}, []any{int64(1), "dup"}),
+			},
+			want:    "a,b",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		for _, inTx := range []bool{false, true} {
+			s := openTestSession(t)
+			if inTx {
+				if err := s.Begin(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := s.Apply(ctx, tt.changes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s (in tx %v): Apply error = %v, want error %v", tt.name, inTx, err, tt.wantErr)
+			}
+			if inTx {
+				if err := s.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := names(t, s); got != tt.want {
+				t.Errorf("%s (in tx %v): rows = %s, want %s", tt.name, inTx, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite" // SQLite driver (pure Go)
+)
+
+func init() {
+	RegisterDialect(sqliteDialect{})
+}
+
+// sqliteDialect implements Dialect for local SQLite database files
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string        { return "sqlite" }
+func (sqliteDialect) DisplayName() string { return "SQLite" }
+func (sqliteDialect) DefaultPort() int    { return 0 }
+func (sqliteDialect) DefaultUser() string { return "" }
+func (sqliteDialect) DriverName() string  { return "sqlite" }
+
+func (sqliteDialect) FileExtensions() []string {
+	return []string{".db", ".sqlite", ".sqlite3", ".db3"}
+}
+
+// DSN builds a file: URI. mode=rw stops SQLite from silently creating a new
+// empty database when the path is mistyped.
+func (sqliteDialect) DSN(p ConnParams) string {
+	path := p.File
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	u := url.URL{
+		Scheme:   "file",
+		Path:     filepath.ToSlash(path),
+		RawQuery: "mode=rw&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)",
+	}
+	return u.String()
+}
+
+func (sqliteDialect) Syntax() Syntax {
+	return Syntax{Backticks: true, Brackets: true, TriggerBodies: true, QuestionParams: true}
+}
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) ListTables(ctx context.Context, q Queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (sqliteDialect) PrimaryKey(ctx context.Context, q Queryer, table string) ([]string, error) {
+	// pk is the 1-based position within the primary key, 0 for other columns
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk", table)
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (d sqliteDialect) TableInfo(ctx context.Context, q Queryer, table string) (TableInfo, error) {
+	row := q.QueryRowContext(ctx, `
+		SELECT
+			'SQLite ' || sqlite_version(),
+			(SELECT count(*) FROM `+d.QuoteIdent(table)+`),
+			(SELECT encoding FROM pragma_encoding)
+	`)
+
+	var info TableInfo
+	err := row.Scan(&info.Engine, &info.Rows, &info.Encoding)
+	return info, err
+}
+
+func (d sqliteDialect) SelectQuery(p Page) string {
+	return selectQuery(d, p)
+}
@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService groups all Kymar items in the OS secret store
+const keyringService = "kymar"
+
+// keyringStore uses the OS secret store: the freedesktop Secret Service on
+// Linux, the Keychain on macOS and the Credential Manager on Windows
+type keyringStore struct{}
+
+// keyringAvailable reports whether the OS secret store answers. A lookup of a
+// missing item succeeds with ErrNotFound when the service is running.
+func keyringAvailable() bool {
+	_, err := keyring.Get(keyringService, "availability-probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (keyringStore) Name() string { return "OS secret store" }
+
+func (keyringStore) Get(key string) (string, error) {
+	v, err := keyring.Get(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	return v, err
+}
+
+func (keyringStore) Set(key, value string) error {
+	return keyring.Set(keyringService, key, value)
+}
+
+func (keyringStore) Delete(key string) error {
+	err := keyring.Delete(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
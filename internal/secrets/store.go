@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned by Get when no secret is stored under the key
+var ErrNotFound = errors.New("secrets: not found")
+
+// ErrCancelled is returned when the user declines to unlock or create a vault
+var ErrCancelled = errors.New("secrets: cancelled")
+
+// Store keeps passwords outside the connections file
+type Store interface {
+	// Name describes the backend for the UI
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// MasterPasswordFunc asks for the vault master password. create is true when
+// no vault exists yet and the password should be chosen and confirmed.
+type MasterPasswordFunc func(create bool) (string, error)
+
+// Open returns the OS secret store (the freedesktop Secret Service over D-Bus
+// on Linux) when it is reachable, and otherwise the encrypted file vault at
+// ~/.kymar/vault.json, unlocked through masterPassword on first use.
+func Open(masterPassword MasterPasswordFunc) (Store, error) {
+	if keyringAvailable() {
+		return keyringStore{}, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewVault(filepath.Join(home, ".kymar", "vault.json"), masterPassword), nil
+}
@@ -0,0 +1,255 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters for new vaults. Existing vaults keep the parameters
+// they were created with.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	keyLen       = 32 // AES-256
+	unlockTries  = 3
+)
+
+// vaultVersion is the format vaults are written in. Version 1 vaults, whose
+// header is not authenticated, are still read and upgraded on the next write.
+const vaultVersion = 2
+
+// ErrWrongPassword is returned when the master password doesn't open the vault
+var ErrWrongPassword = errors.New("secrets: wrong master password")
+
+// vaultFile is the on-disk vault format. Data is the AES-GCM encrypted JSON
+// map of secrets, with the header authenticated along with it.
+type vaultFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// additionalData returns what GCM authenticates besides the secrets: the
+// header, so tampering with the KDF parameters or salt fails to open the vault
+func (f vaultFile) additionalData() []byte {
+	if f.Version < 2 {
+		return nil
+	}
+	header := f
+	header.Nonce, header.Data = nil, nil
+	data, _ := json.Marshal(header)
+	return data
+}
+
+// Vault is a Store kept in a file encrypted with a key derived from a master
+// password (argon2id + AES-256-GCM). It is unlocked on first use.
+type Vault struct {
+	path           string
+	masterPassword MasterPasswordFunc
+
+	mu       sync.Mutex
+	header   vaultFile // KDF parameters and salt of the open vault
+	key      []byte
+	items    map[string]string
+	declined bool // The user cancelled unlocking; don't ask again this session
+}
+
+// NewVault returns a vault stored at path
+func NewVault(path string, masterPassword MasterPasswordFunc) *Vault {
+	return &Vault{path: path, masterPassword: masterPassword}
+}
+
+func (v *Vault) Name() string { return "encrypted vault" }
+
+func (v *Vault) Get(key string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.unlock(); err != nil {
+		return "", err
+	}
+	value, ok := v.items[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (v *Vault) Set(key, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.unlock(); err != nil {
+		return err
+	}
+	v.items[key] = value
+	return v.write()
+}
+
+func (v *Vault) Delete(key string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Without a vault there is nothing to delete, and no reason to ask for
+	// a master password to create one
+	if v.items == nil {
+		if _, err := os.Stat(v.path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	if err := v.unlock(); err != nil {
+		return err
+	}
+	if _, ok := v.items[key]; !ok {
+		return nil
+	}
+	delete(v.items, key)
+	return v.write()
+}
+
+// unlock opens the vault, or prepares a new one when the file doesn't exist.
+// Callers must hold v.mu.
+func (v *Vault) unlock() error {
+	if v.items != nil {
+		return nil
+	}
+	if v.declined || v.masterPassword == nil {
+		return ErrCancelled
+	}
+
+	data, err := os.ReadFile(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return v.create()
+	}
+	if err != nil {
+		return err
+	}
+
+	var f vaultFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("secrets: corrupt vault %s: %w", v.path, err)
+	}
+	if f.Version < 1 || f.Version > vaultVersion || f.KDF != "argon2id" {
+		return fmt.Errorf("secrets: unsupported vault format in %s", v.path)
+	}
+
+	for try := 0; try < unlockTries; try++ {
+		password, err := v.masterPassword(false)
+		if err != nil {
+			v.declined = errors.Is(err, ErrCancelled)
+			return err
+		}
+
+		key := argon2.IDKey([]byte(password), f.Salt, f.Time, f.Memory, f.Threads, keyLen)
+		plain, err := open(key, f.Nonce, f.Data, f.additionalData())
+		if err != nil {
+			continue // Wrong password: GCM authentication failed
+		}
+
+		items := map[string]string{}
+		if err := json.Unmarshal(plain, &items); err != nil {
+			return fmt.Errorf("secrets: corrupt vault %s: %w", v.path, err)
+		}
+		v.header, v.key, v.items = f, key, items
+		return nil
+	}
+	return ErrWrongPassword
+}
+
+// create sets up an empty vault with a new master password. The file is
+// written on the first Set. Callers must hold v.mu.
+func (v *Vault) create() error {
+	password, err := v.masterPassword(true)
+	if err != nil {
+		v.declined = errors.Is(err, ErrCancelled)
+		return err
+	}
+	if password == "" {
+		return errors.New("secrets: master password must not be empty")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	v.header = vaultFile{
+		Version: vaultVersion,
+		KDF:     "argon2id",
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+		Salt:    salt,
+	}
+	v.key = argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, keyLen)
+	v.items = map[string]string{}
+	return nil
+}
+
+// write encrypts the items with a fresh nonce and replaces the vault file
+// atomically. Callers must hold v.mu.
+func (v *Vault) write() error {
+	plain, err := json.Marshal(v.items)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(v.key)
+	if err != nil {
+		return err
+	}
+	f := v.header
+	f.Version = vaultVersion
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, f.additionalData())
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return err
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, v.path)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// open decrypts data, failing when the key is wrong or the data or
+// additional data was altered
+func open(key, nonce, data, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("secrets: invalid nonce")
+	}
+	return gcm.Open(nil, nonce, data, additional)
+}
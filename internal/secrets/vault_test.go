@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixedPassword returns a MasterPasswordFunc answering password and counting
+// the prompts
+func fixedPassword(password string, prompts *int) MasterPasswordFunc {
+	return func(bool) (string, error) {
+		*prompts++
+		return password, nil
+	}
+}
+
+func TestVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	var prompts int
+
+	v := NewVault(path, fixedPassword("secret", &prompts))
+	if err := v.Set("a", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := NewVault(path, fixedPassword("secret", &prompts))
+	got, err := reopened.Get("a")
+	if err != nil || got != "1" {
+		t.Fatalf("Get = %q, %v; want 1", got, err)
+	}
+	if _, err := reopened.Get("b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing key = %v, want ErrNotFound", err)
+	}
+
+	wrong := NewVault(path, fixedPassword("guess", &prompts))
+	if _, err := wrong.Get("a"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Get with a wrong password = %v, want ErrWrongPassword", err)
+	}
+}
+
+func TestVaultDeleteWithoutFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	var prompts int
+
+	v := NewVault(path, fixedPassword("secret", &prompts))
+	if err := v.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if prompts != 0 {
+		t.Errorf("Delete prompted %d time(s) without a vault", prompts)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Delete created the vault: %v", err)
+	}
+}
+
+func TestVaultHeaderAuthenticated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	var prompts int
+
+	if err := NewVault(path, fixedPassword("secret", &prompts)).Set("a", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f vaultFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	for name, tamper := range map[string]func(*vaultFile){
+		"time":    func(f *vaultFile) { f.Time++ },
+		"version": func(f *vaultFile) { f.Version = 1 },
+	} {
+		changed := f
+		tamper(&changed)
+		data, _ := json.Marshal(changed)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewVault(path, fixedPassword("secret", &prompts)).Get("a"); !errors.Is(err, ErrWrongPassword) {
+			t.Errorf("%s changed: Get = %v, want ErrWrongPassword", name, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrCancelled is returned when the user dismisses a prompt
+var ErrCancelled = errors.New("ssh: connection cancelled")
+
+// Auth holds the credentials used to log in to an SSH server
+type Auth struct {
+	User     string
+	Password string
+	KeyFile  string // Private key file, "~" is expanded
+	UseAgent bool   // Try keys from the agent at SSH_AUTH_SOCK
+}
+
+// Prompter asks the user for input while connecting. Implementations may
+// block until the user answers.
+type Prompter interface {
+	// Passphrase asks for the passphrase of an encrypted private key
+	Passphrase(keyFile string) (string, error)
+	// Challenge answers a keyboard-interactive challenge (e.g. an MFA code)
+	Challenge(user, instruction string, questions []string, echos []bool) ([]string, error)
+	// ConfirmHostKey asks whether to trust a host seen for the first time
+	ConfirmHostKey(host, keyType, fingerprint string) (bool, error)
+}
+
+// authSession builds the auth method chain for one login. Close releases the
+// agent connection once the handshake is done.
+type authSession struct {
+	auth    Auth
+	prompt  Prompter
+	agentNC net.Conn
+}
+
+// methods returns the auth methods in the order they are tried: public keys
+// (agent first, then the key file), password, then keyboard-interactive.
+func (s *authSession) methods() []ssh.AuthMethod {
+	var methods []ssh.AuthMethod
+
+	if s.auth.UseAgent || s.auth.KeyFile != "" {
+		// The client tries each method type once, so agent and file keys must
+		// share a single publickey method
+		methods = append(methods, ssh.PublicKeysCallback(s.signers))
+	}
+	if s.auth.Password != "" {
+		methods = append(methods, ssh.Password(s.auth.Password))
+	}
+	methods = append(methods, ssh.KeyboardInteractive(s.challenge))
+	return methods
+}
+
+// signers collects agent keys and the private key file. It runs lazily so a
+// passphrase is only requested when the server asks for public key auth.
+func (s *authSession) signers() ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+
+	if s.auth.UseAgent {
+		if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+			if nc, err := net.Dial("unix", sock); err == nil {
+				s.agentNC = nc
+				if agentSigners, err := agent.NewClient(nc).Signers(); err == nil {
+					signers = append(signers, agentSigners...)
+				}
+			}
+		}
+	}
+
+	if s.auth.KeyFile != "" {
+		signer, err := s.loadKey(s.auth.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// loadKey parses a private key file, prompting for a passphrase if needed
+func (s *authSession) loadKey(path string) (ssh.Signer, error) {
+	path = ExpandHome(path)
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(pem)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return signer, err
+	}
+	if s.prompt == nil {
+		return nil, fmt.Errorf("private key %s is encrypted", path)
+	}
+
+	passphrase, err := s.prompt.Passphrase(path)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+}
+
+// challenge answers keyboard-interactive prompts. Servers that ask only for
+// the password this way are answered without bothering the user.
+func (s *authSession) challenge(name, instruction string, questions []string, echos []bool) ([]string, error) {
+	if len(questions) == 0 {
+		return nil, nil
+	}
+	if s.auth.Password != "" && len(questions) == 1 && !echos[0] &&
+		strings.Contains(strings.ToLower(questions[0]), "password") {
+		return []string{s.auth.Password}, nil
+	}
+	if s.prompt == nil {
+		return nil, errors.New("ssh: server requires interactive authentication")
+	}
+	if instruction == "" {
+		instruction = name
+	}
+	return s.prompt.Challenge(s.auth.User, instruction, questions, echos)
+}
+
+func (s *authSession) Close() {
+	if s.agentNC != nil {
+		_ = s.agentNC.Close()
+		s.agentNC = nil
+	}
+}
+
+// ExpandHome replaces a leading "~" with the user's home directory
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
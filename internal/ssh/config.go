@@ -0,0 +1,208 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+// maxJumpDepth bounds ProxyJump recursion so a config loop can't hang dialing
+const maxJumpDepth = 8
+
+// HostConfig holds the settings ~/.ssh/config defines for a host alias.
+// Values the config doesn't set are left empty.
+type HostConfig struct {
+	HostName     string
+	Port         int
+	User         string
+	IdentityFile string
+	ProxyJump    string // Comma-separated [user@]host[:port] list
+}
+
+// ResolveHost looks up alias in ~/.ssh/config, following Include directives
+// and wildcard Host blocks. A missing config file is not an error.
+func ResolveHost(alias string) (HostConfig, error) {
+	var hc HostConfig
+
+	path := ExpandHome("~/.ssh/config")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return hc, nil
+	}
+	if err != nil {
+		return hc, err
+	}
+	cfg, err := ssh_config.DecodeBytes(data)
+	if err != nil {
+		return hc, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	get := func(key string) string {
+		v, _ := cfg.Get(alias, key)
+		return v
+	}
+
+	hc.User = get("User")
+	// %h in HostName itself can only mean the alias, as in OpenSSH
+	hc.HostName = expandTokens(get("HostName"), alias, alias, hc.User)
+	host := hc.HostName
+	if host == "" {
+		host = alias
+	}
+	if port := get("Port"); port != "" {
+		if hc.Port, err = strconv.Atoi(port); err != nil {
+			return hc, fmt.Errorf("invalid Port %q for %s in %s", port, alias, path)
+		}
+	}
+	if id := get("IdentityFile"); id != "" {
+		hc.IdentityFile = ExpandHome(expandTokens(id, alias, host, hc.User))
+	}
+	if jump := get("ProxyJump"); !strings.EqualFold(jump, "none") {
+		hc.ProxyJump = jump
+	}
+	return hc, nil
+}
+
+// expandTokens replaces the ssh_config percent tokens relevant to us. %n is
+// the alias as given and %h the host name it resolved to.
+func expandTokens(s, alias, host, remoteUser string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	home, _ := os.UserHomeDir()
+	localUser := ""
+	if u, err := user.Current(); err == nil {
+		localUser = u.Username
+	}
+	if remoteUser == "" {
+		remoteUser = localUser
+	}
+	return strings.NewReplacer(
+		"%%", "%",
+		"%h", host,
+		"%n", alias,
+		"%d", home,
+		"%u", localUser,
+		"%r", remoteUser,
+	).Replace(s)
+}
+
+// applyConfig fills the hop's empty fields from ~/.ssh/config, treating
+// Host as an alias. Explicit values always win. A config that can't be read
+// or parsed, e.g. one using Match, just provides no alias information.
+func applyConfig(hop Hop) (Hop, HostConfig) {
+	hc, err := ResolveHost(hop.Host)
+	if err != nil {
+		hc = HostConfig{}
+	}
+	if hc.HostName != "" {
+		hop.Host = hc.HostName
+	}
+	if hop.Port == 0 {
+		hop.Port = hc.Port
+	}
+	if hop.Auth.User == "" {
+		hop.Auth.User = hc.User
+	}
+	if hop.Auth.User == "" {
+		// Like OpenSSH, fall back to the local user name
+		if u, err := user.Current(); err == nil {
+			hop.Auth.User = u.Username
+		}
+	}
+	if hop.Auth.KeyFile == "" && hc.IdentityFile != "" {
+		if _, err := os.Stat(hc.IdentityFile); err == nil {
+			hop.Auth.KeyFile = hc.IdentityFile
+		}
+	}
+	return hop, hc
+}
+
+// resolveHops applies ~/.ssh/config to every hop. A ProxyJump on the first
+// hop is expanded into jump hops in front of it; later hops already have an
+// explicit predecessor, so their ProxyJump is ignored.
+func resolveHops(hops []Hop) ([]Hop, error) {
+	var out []Hop
+	for i, hop := range hops {
+		resolved, hc := applyConfig(hop)
+		if i == 0 && hc.ProxyJump != "" {
+			jumps, err := proxyJumpHops(hc.ProxyJump, hop.Auth.UseAgent, 1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, jumps...)
+		}
+		out = append(out, resolved)
+	}
+	return out, nil
+}
+
+// proxyJumpHops turns a ProxyJump value into hops, resolving each jump host
+// (and its own ProxyJump) through ~/.ssh/config
+func proxyJumpHops(spec string, useAgent bool, depth int) ([]Hop, error) {
+	if depth > maxJumpDepth {
+		return nil, errors.New("ssh: ProxyJump chain too deep (loop in ~/.ssh/config?)")
+	}
+
+	var out []Hop
+	for i, part := range strings.Split(spec, ",") {
+		hop, err := ParseJump(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		hop.Auth.UseAgent = useAgent
+
+		resolved, hc := applyConfig(hop)
+		if i == 0 && hc.ProxyJump != "" {
+			jumps, err := proxyJumpHops(hc.ProxyJump, useAgent, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, jumps...)
+		}
+		out = append(out, resolved)
+	}
+	return out, nil
+}
+
+// ParseJump parses one [user@]host[:port] ProxyJump element
+func ParseJump(s string) (Hop, error) {
+	var hop Hop
+	if s == "" {
+		return hop, errors.New("ssh: empty jump host")
+	}
+	if at := strings.LastIndex(s, "@"); at >= 0 {
+		hop.Auth.User, s = s[:at], s[at+1:]
+	}
+
+	hop.Host = s
+	if strings.HasPrefix(s, "[") {
+		// [ipv6]:port
+		if end := strings.Index(s, "]"); end > 0 {
+			hop.Host = s[1:end]
+			s = strings.TrimPrefix(s[end+1:], ":")
+			if s == "" {
+				return hop, nil
+			}
+			port, err := strconv.Atoi(s)
+			if err != nil {
+				return hop, fmt.Errorf("ssh: invalid jump host port %q", s)
+			}
+			hop.Port = port
+		}
+		return hop, nil
+	}
+	if colon := strings.LastIndex(s, ":"); colon >= 0 && strings.Count(s, ":") == 1 {
+		port, err := strconv.Atoi(s[colon+1:])
+		if err != nil {
+			return hop, fmt.Errorf("ssh: invalid jump host port %q", s[colon+1:])
+		}
+		hop.Host, hop.Port = s[:colon], port
+	}
+	return hop, nil
+}
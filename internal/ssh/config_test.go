@@ -0,0 +1,35 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveHostTokens(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := `Host bastion
+  HostName %h.example.com
+  User deploy
+  IdentityFile ~/.ssh/%n-%h-%r
+`
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hc, err := ResolveHost("bastion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "bastion.example.com"; hc.HostName != want {
+		t.Errorf("HostName = %q, want %q", hc.HostName, want)
+	}
+	if want := filepath.Join(home, ".ssh", "bastion-bastion.example.com-deploy"); hc.IdentityFile != want {
+		t.Errorf("IdentityFile = %q, want %q", hc.IdentityFile, want)
+	}
+}
@@ -0,0 +1,166 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostKeyChangedError is returned when a server presents a key that differs
+// from the ones on record, whatever its type. It is never bypassed by a prompt.
+type HostKeyChangedError struct {
+	Host        string
+	Fingerprint string
+	Files       []string // known_hosts files holding the old key
+}
+
+func (e *HostKeyChangedError) Error() string {
+	return fmt.Sprintf("WARNING: REMOTE HOST IDENTIFICATION HAS CHANGED for %s!\n\n"+
+		"The server now presents %s. Someone could be intercepting the connection, "+
+		"or the host key has just been rotated.\n\n"+
+		"If the change is expected, remove the old entry from %s and connect again.",
+		e.Host, e.Fingerprint, strings.Join(e.Files, " or "))
+}
+
+// knownHostsFiles returns the user's OpenSSH known_hosts followed by the
+// app-managed one, as paths (they may not exist yet)
+func knownHostsFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".ssh", "known_hosts"),
+		filepath.Join(home, ".kymar", "known_hosts"),
+	}
+}
+
+// probeKey is a key no known_hosts line matches, checked to list the keys
+// on record for a host
+type probeKey struct{}
+
+func (probeKey) Type() string                        { return "kymar-probe" }
+func (probeKey) Marshal() []byte                     { return []byte("kymar-probe") }
+func (probeKey) Verify([]byte, *ssh.Signature) error { return errors.New("ssh: probe key") }
+
+// knownHostsCallback returns the known_hosts check over the files that
+// exist, or nil when there are none
+func knownHostsCallback() (ssh.HostKeyCallback, error) {
+	var existing []string
+	for _, f := range knownHostsFiles() {
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+	check, err := knownhosts.New(existing...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+	return check, nil
+}
+
+// knownHostKeyAlgorithms returns the host key algorithms of the keys on
+// record for address, so that a known host is only asked for a key that can
+// be verified. It returns nil for unknown hosts.
+func knownHostKeyAlgorithms(address string) ([]string, error) {
+	check, err := knownHostsCallback()
+	if check == nil || err != nil {
+		return nil, err
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(check(address, &net.TCPAddr{}, probeKey{}), &keyErr) {
+		return nil, nil
+	}
+
+	var algorithms []string
+	for _, want := range keyErr.Want {
+		algos := []string{want.Key.Type()}
+		if algos[0] == ssh.KeyAlgoRSA {
+			// RSA keys sign with SHA-2 on current servers
+			algos = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, a := range algos {
+			if !slices.Contains(algorithms, a) {
+				algorithms = append(algorithms, a)
+			}
+		}
+	}
+	return algorithms, nil
+}
+
+// hostKeyCallback checks server keys against known_hosts. Unknown hosts are
+// confirmed through prompt and remembered in ~/.kymar/known_hosts.
+func hostKeyCallback(prompt Prompter) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+
+		check, err := knownHostsCallback()
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			err = check(hostname, remote, key)
+
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) {
+				return err // Known key, revoked key, or I/O error
+			}
+
+			// Any other key from a host on record, even of a type not
+			// recorded for it, means the host key changed: only hosts
+			// without keys are confirmed through the prompt
+			var files []string
+			for _, want := range keyErr.Want {
+				if !slices.Contains(files, want.Filename) {
+					files = append(files, want.Filename)
+				}
+			}
+			if len(files) > 0 {
+				return &HostKeyChangedError{Host: hostname, Fingerprint: fingerprint, Files: files}
+			}
+		}
+
+		if prompt == nil {
+			return fmt.Errorf("ssh: host key for %s is not known (%s)", hostname, fingerprint)
+		}
+		trusted, err := prompt.ConfirmHostKey(hostname, key.Type(), fingerprint)
+		if err != nil {
+			return err
+		}
+		if !trusted {
+			return ErrCancelled
+		}
+		return rememberHostKey(hostname, key)
+	}
+}
+
+// rememberHostKey appends key to the app-managed known_hosts file
+func rememberHostKey(hostname string, key ssh.PublicKey) error {
+	files := knownHostsFiles()
+	if len(files) == 0 {
+		return errors.New("ssh: cannot locate home directory for known_hosts")
+	}
+	path := files[len(files)-1]
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+	return err
+}
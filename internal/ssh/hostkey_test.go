@@ -0,0 +1,75 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// refusingPrompter fails the test when asked to trust a host key
+type refusingPrompter struct {
+	Prompter
+	t *testing.T
+}
+
+func (p refusingPrompter) ConfirmHostKey(host, keyType, fingerprint string) (bool, error) {
+	p.t.Errorf("prompted to trust %s key %s of %s", keyType, fingerprint, host)
+	return false, nil
+}
+
+func TestKnownHostKeys(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := ssh.NewPublicKey(edPriv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := ssh.NewPublicKey(rsaPriv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := knownhosts.Line([]string{knownhosts.Normalize("db.example:22")}, edKey)
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	algorithms, err := knownHostKeyAlgorithms("db.example:22")
+	if err != nil || !slices.Equal(algorithms, []string{ssh.KeyAlgoED25519}) {
+		t.Errorf("algorithms of a known host = %v, %v; want [%s]", algorithms, err, ssh.KeyAlgoED25519)
+	}
+	if algorithms, err := knownHostKeyAlgorithms("other.example:22"); err != nil || algorithms != nil {
+		t.Errorf("algorithms of an unknown host = %v, %v; want none", algorithms, err)
+	}
+
+	check := hostKeyCallback(refusingPrompter{t: t})
+	remote := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}
+	if err := check("db.example:22", remote, edKey); err != nil {
+		t.Errorf("known key: %v", err)
+	}
+	var changed *HostKeyChangedError
+	if err := check("db.example:22", remote, rsaKey); !errors.As(err, &changed) {
+		t.Errorf("key of another type from a known host = %v, want HostKeyChangedError", err)
+	}
+}
@@ -1,24 +1,154 @@
 package ssh
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"golang.org/x/crypto/ssh"
 )
 
-// NewTunnelDialer creates a new SSH tunnel dialer
-func NewTunnelDialer(host string, port int, user, pass string) (func(network, addr string) (net.Conn, error), func() error, error) {
-	cfg := &ssh.ClientConfig{
-		User:            user,
-		Auth:            []ssh.AuthMethod{ssh.Password(pass)},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // TODO: replace with known_hosts for production
-		Timeout:         5 * time.Second,
+// Hop is one SSH server in a tunnel chain, with its own credentials
+type Hop struct {
+	Host string
+	Port int
+	Auth Auth
+}
+
+// Addr returns the host:port to dial for the hop
+func (h Hop) Addr() string {
+	port := h.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(h.Host, strconv.Itoa(port))
+}
+
+// NewTunnelDialer creates a new SSH tunnel dialer through hops, in order:
+// each hop is reached through the previous one, like OpenSSH's ProxyJump.
+// Hop hosts may be ~/.ssh/config aliases; see resolveHops.
+// Host keys are verified against known_hosts. prompt may be nil, in which
+// case unknown hosts, encrypted keys and interactive challenges fail instead
+// of asking. timeout bounds connecting to and the handshake with each hop,
+// not counting the time spent answering prompts; 0 means no limit. The
+// returned closer tears down the whole chain.
+func NewTunnelDialer(hops []Hop, prompt Prompter, timeout time.Duration) (func(ctx context.Context, network, addr string) (net.Conn, error), func() error, error) {
+	if len(hops) == 0 {
+		return nil, nil, errors.New("ssh: no hosts to tunnel through")
 	}
-	c, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", host, port), cfg)
+	hops, err := resolveHops(hops)
 	if err != nil {
 		return nil, nil, err
 	}
-	return func(network, addr string) (net.Conn, error) { return c.Dial("tcp", addr) }, c.Close, nil
+
+	var clients []*ssh.Client
+	closeAll := func() error {
+		var errs []error
+		for i := len(clients) - 1; i >= 0; i-- {
+			errs = append(errs, clients[i].Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	for i, hop := range hops {
+		var prev *ssh.Client
+		if i > 0 {
+			prev = clients[i-1]
+		}
+		c, err := dialHop(prev, hop, prompt, timeout)
+		if err != nil {
+			_ = closeAll()
+			if len(hops) > 1 {
+				err = fmt.Errorf("hop %d (%s): %w", i+1, hop.Addr(), err)
+			}
+			return nil, nil, err
+		}
+		clients = append(clients, c)
+	}
+
+	last := clients[len(clients)-1]
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return last.DialContext(ctx, "tcp", addr)
+	}
+	return dial, closeAll, nil
+}
+
+// dialHop connects to hop directly, or through prev when it is not nil
+func dialHop(prev *ssh.Client, hop Hop, prompt Prompter, timeout time.Duration) (*ssh.Client, error) {
+	addr := hop.Addr()
+	algorithms, err := knownHostKeyAlgorithms(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var conn net.Conn
+	if prev == nil {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = prev.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Jump hop connections have no deadlines: a timer closes the connection
+	// if the handshake doesn't finish in time
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() { _ = conn.Close() })
+		defer timer.Stop()
+		if prompt != nil {
+			prompt = timedPrompter{Prompter: prompt, timer: timer, timeout: timeout}
+		}
+	}
+
+	session := &authSession{auth: hop.Auth, prompt: prompt}
+	defer session.Close()
+
+	cfg := &ssh.ClientConfig{
+		User:              hop.Auth.User,
+		Auth:              session.methods(),
+		HostKeyCallback:   hostKeyCallback(prompt),
+		HostKeyAlgorithms: algorithms,
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// timedPrompter stops the handshake timer while the user answers a prompt
+type timedPrompter struct {
+	Prompter
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (p timedPrompter) Passphrase(keyFile string) (string, error) {
+	p.timer.Stop()
+	defer p.timer.Reset(p.timeout)
+	return p.Prompter.Passphrase(keyFile)
+}
+
+func (p timedPrompter) Challenge(user, instruction string, questions []string, echos []bool) ([]string, error) {
+	p.timer.Stop()
+	defer p.timer.Reset(p.timeout)
+	return p.Prompter.Challenge(user, instruction, questions, echos)
+}
+
+func (p timedPrompter) ConfirmHostKey(host, keyType, fingerprint string) (bool, error) {
+	p.timer.Stop()
+	defer p.timer.Reset(p.timeout)
+	return p.Prompter.ConfirmHostKey(host, keyType, fingerprint)
 }
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// sqlEditor is the multi-line query editor. A focused Entry receives every
+// shortcut and drops the ones it doesn't know, so the editor handles its own.
+type sqlEditor struct {
+	widget.Entry
+	shortcuts map[string]func()
+}
+
+func newSQLEditor() *sqlEditor {
+	e := &sqlEditor{shortcuts: map[string]func(){}}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrap(fyne.TextTruncateClip)
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// AddShortcut runs fn when s is typed in the editor
+func (e *sqlEditor) AddShortcut(s fyne.Shortcut, fn func()) {
+	e.shortcuts[s.ShortcutName()] = fn
+}
+
+// TypedShortcut implements fyne.Shortcutable
+func (e *sqlEditor) TypedShortcut(s fyne.Shortcut) {
+	if fn, ok := e.shortcuts[s.ShortcutName()]; ok {
+		fn()
+		return
+	}
+	e.Entry.TypedShortcut(s)
+}
+
+// CursorOffset returns the byte offset of the cursor in Text. The editor
+// doesn't wrap, so its rows are the lines of the text.
+func (e *sqlEditor) CursorOffset() int {
+	offset := 0
+	for i, line := range strings.Split(e.Text, "\n") {
+		if i == e.CursorRow {
+			col := []rune(line)
+			return offset + len(string(col[:min(e.CursorColumn, len(col))]))
+		}
+		offset += len(line) + 1
+	}
+	return len(e.Text)
+}
@@ -0,0 +1,342 @@
+package ui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pn/kymar/internal/db"
+)
+
+// Markers of pending changes
+var (
+	editedColor  = color.RGBA{R: 230, G: 160, B: 0, A: 110} // Changed cells
+	addedColor   = color.RGBA{R: 40, G: 170, B: 70, A: 90}  // Rows to insert
+	deletedColor = color.RGBA{R: 210, G: 50, B: 50, A: 110} // Rows to delete
+)
+
+// defaultValue is shown in cells of new rows left to the column default
+const defaultValue = "DEFAULT"
+
+// cell addresses a data cell of a result, not counting the header row
+type cell struct {
+	row, col int
+}
+
+// cellEdit is the pending new value of a cell: text entered, or a value
+// copied as the driver returned it
+type cellEdit struct {
+	value string
+	null  bool
+	raw   any // Copied value, bound instead of value when set
+}
+
+// text is the value as the results grid shows it
+func (e cellEdit) text() string {
+	if e.null {
+		return "NULL"
+	}
+	return e.value
+}
+
+// arg is the value bound to the UPDATE
+func (e cellEdit) arg() any {
+	if e.null {
+		return nil
+	}
+	if e.raw != nil {
+		return e.raw
+	}
+	return e.value
+}
+
+// isNull reports whether the value of c as read is NULL. A 'NULL' string
+// shows the same, but isn't.
+func (rs *resultSet) isNull(c cell) bool {
+	return rs.values[c.row][c.col] == nil
+}
+
+// current returns the value of c as read, as an edit that changes nothing
+func (rs *resultSet) current(c cell) cellEdit {
+	if rs.isNull(c) {
+		return cellEdit{null: true}
+	}
+	return cellEdit{value: rs.rows[c.row][c.col], raw: rs.values[c.row][c.col]}
+}
+
+// editable reports whether rs holds rows browsed from a table whose primary
+// key is known, so they can be edited
+func (rs *resultSet) editable() bool {
+	return rs.source != "" && len(rs.key) > 0
+}
+
+// setEdit queues a new value for c. A value equal to the current one drops
+// the pending change instead, except in new rows.
+func (rs *resultSet) setEdit(c cell, e cellEdit) {
+	if cur := rs.current(c); !rs.added[c.row] && e.null == cur.null && e.value == cur.value {
+		delete(rs.edits, c)
+		return
+	}
+	if rs.edits == nil {
+		rs.edits = map[cell]cellEdit{}
+	}
+	rs.edits[c] = e
+}
+
+// addRow appends a new row to insert, with every column left to its
+// default, and returns its index
+func (rs *resultSet) addRow() int {
+	row := make([]string, len(rs.columnNames))
+	for i := range row {
+		row[i] = defaultValue
+	}
+	rs.rows = append(rs.rows, row)
+	rs.values = append(rs.values, make([]any, len(row)))
+	if rs.added == nil {
+		rs.added = map[int]bool{}
+	}
+	rs.added[len(rs.rows)-1] = true
+	return len(rs.rows) - 1
+}
+
+// duplicateRow appends a new row to insert with the values of row. The
+// primary key is left to its default, since copying it would clash.
+func (rs *resultSet) duplicateRow(row int) int {
+	dup := rs.addRow()
+	for col := range rs.columnNames {
+		if slices.Contains(rs.key, col) {
+			continue
+		}
+		e, ok := rs.edits[cell{row, col}]
+		if !ok && !rs.added[row] {
+			e, ok = rs.current(cell{row, col}), true
+		}
+		if ok {
+			rs.setEdit(cell{dup, col}, e)
+		}
+	}
+	return dup
+}
+
+// toggleDelete marks row for deletion, or unmarks it
+func (rs *resultSet) toggleDelete(row int) {
+	if rs.deleted[row] {
+		delete(rs.deleted, row)
+		return
+	}
+	if rs.deleted == nil {
+		rs.deleted = map[int]bool{}
+	}
+	rs.deleted[row] = true
+}
+
+// pending counts the rows with changes to save
+func (rs *resultSet) pending() int {
+	rows := map[int]bool{}
+	for c := range rs.edits {
+		rows[c.row] = true
+	}
+	for row := range rs.added {
+		rows[row] = true
+	}
+	for row := range rs.deleted {
+		rows[row] = true
+	}
+	n := 0
+	for row := range rows {
+		// New rows deleted again cancel out
+		if !rs.added[row] || !rs.deleted[row] {
+			n++
+		}
+	}
+	return n
+}
+
+// reshaped reports whether the pending changes add or delete rows
+func (rs *resultSet) reshaped() bool {
+	return len(rs.added) > 0 || len(rs.deleted) > 0
+}
+
+// changes turns the pending changes into statements: DELETEs, then one
+// UPDATE per changed row, then INSERTs. Existing rows are addressed by the
+// primary key values they were read with.
+func (rs *resultSet) changes(d db.Dialect) []db.Change {
+	keyColumns := make([]string, len(rs.key))
+	for i, col := range rs.key {
+		keyColumns[i] = rs.columnNames[col]
+	}
+	keyValues := func(row int) []any {
+		values := make([]any, len(rs.key))
+		for i, col := range rs.key {
+			values[i] = rs.values[row][col]
+		}
+		return values
+	}
+	// set returns the edited columns of row and their new values
+	set := func(row int) ([]string, []any) {
+		var columns []string
+		var values []any
+		for col, name := range rs.columnNames {
+			if e, ok := rs.edits[cell{row, col}]; ok {
+				columns = append(columns, name)
+				values = append(values, e.arg())
+			}
+		}
+		return columns, values
+	}
+
+	var deletes, updates, inserts []db.Change
+	for row := range rs.rows {
+		switch {
+		case rs.added[row] && rs.deleted[row]:
+		case rs.deleted[row]:
+			deletes = append(deletes, db.DeleteRow(d, rs.source, keyColumns, keyValues(row)))
+		case rs.added[row]:
+			columns, values := set(row)
+			inserts = append(inserts, db.InsertRow(d, rs.source, columns, values))
+		default:
+			if columns, values := set(row); len(columns) > 0 {
+				updates = append(updates, db.UpdateRow(d, rs.source, columns, values, keyColumns, keyValues(row)))
+			}
+		}
+	}
+	return slices.Concat(deletes, updates, inserts)
+}
+
+// commitEdits writes the pending changes into the rows once they are saved.
+// Values the database filled in for new rows stay unknown until a reload.
+func (rs *resultSet) commitEdits() {
+	for c, e := range rs.edits {
+		rs.rows[c.row][c.col] = e.text()
+		rs.values[c.row][c.col] = e.arg()
+	}
+	if len(rs.deleted) > 0 {
+		kept, keptValues := rs.rows[:0], rs.values[:0]
+		for row, text := range rs.rows {
+			if !rs.deleted[row] {
+				kept = append(kept, text)
+				keptValues = append(keptValues, rs.values[row])
+			}
+		}
+		rs.rows, rs.values = kept, keptValues
+		rs.selectedRow = -1
+	}
+	rs.edits, rs.added, rs.deleted = nil, nil, nil
+}
+
+// discardEdits drops the pending changes, new rows included
+func (rs *resultSet) discardEdits() {
+	if len(rs.added) > 0 {
+		// New rows are always the last ones
+		rs.rows = rs.rows[:len(rs.rows)-len(rs.added)]
+		rs.values = rs.values[:len(rs.values)-len(rs.added)]
+		if rs.selectedRow >= len(rs.rows) {
+			rs.selectedRow = -1
+		}
+	}
+	rs.edits, rs.added, rs.deleted = nil, nil, nil
+}
+
+// showCellEditor edits the value of c in rs and calls onChange once the
+// pending changes of rs changed
+func showCellEditor(w fyne.Window, rs *resultSet, c cell, onChange func()) {
+	current := rs.current(c)
+	if e, ok := rs.edits[c]; ok {
+		current = e
+	} else if rs.added[c.row] {
+		current = cellEdit{}
+	}
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(current.value)
+	entry.SetMinRowsVisible(4)
+	null := widget.NewCheck("NULL", func(on bool) {
+		if on {
+			entry.Disable()
+		} else {
+			entry.Enable()
+		}
+	})
+	null.SetChecked(current.null)
+
+	title := fmt.Sprintf("Edit %s", rs.columnNames[c.col])
+	content := container.NewBorder(nil, null, nil, nil, entry)
+	d := dialog.NewCustomConfirm(title, "OK", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		next := cellEdit{value: entry.Text, null: null.Checked}
+		if next.value == current.value && next.null == current.null {
+			next = current // Keep a copied value as it was
+		}
+		rs.setEdit(c, next)
+		rs.refresh()
+		onChange()
+	}, w)
+	d.Resize(fyne.NewSize(460, 0))
+	d.Show()
+}
+
+// previewSQL lists changes as the statements run, each followed by its
+// arguments
+func previewSQL(changes []db.Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		b.WriteString(c.Query + ";\n")
+		if len(c.Args) == 0 {
+			continue
+		}
+		args := make([]string, len(c.Args))
+		for i, a := range c.Args {
+			args[i] = sqlLiteral(a)
+		}
+		b.WriteString("-- " + strings.Join(args, ", ") + "\n")
+	}
+	return b.String()
+}
+
+// sqlLiteral writes a driver value as an SQL literal. Bytes that aren't
+// text, such as BLOBs, show as a hex literal.
+func sqlLiteral(v any) string {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quote(v)
+	case []byte:
+		if utf8.Valid(v) {
+			return quote(string(v))
+		}
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+	case time.Time:
+		return quote(formatValue(v))
+	}
+	return formatValue(v)
+}
+
+// showChangesPreview shows the statements saving changes and calls onSave
+// once confirmed
+func showChangesPreview(w fyne.Window, changes []db.Change, onSave func()) {
+	preview := widget.NewLabel(previewSQL(changes))
+	preview.TextStyle = fyne.TextStyle{Monospace: true}
+	preview.Selectable = true
+	scroll := container.NewScroll(preview)
+	scroll.SetMinSize(fyne.NewSize(640, 280))
+
+	d := dialog.NewCustomConfirm(fmt.Sprintf("Save %d Change(s)", len(changes)), "Save", "Cancel", scroll, func(ok bool) {
+		if ok {
+			onSave()
+		}
+	}, w)
+	d.Show()
+}
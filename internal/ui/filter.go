@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pn/kymar/internal/db"
+)
+
+// Ways a filter combines its rules
+const (
+	matchAll = "Match all (AND)"
+	matchAny = "Match any (OR)"
+)
+
+// filterBar edits the filter of a browsed table, one row per rule
+type filterBar struct {
+	content *fyne.Container
+	rules   *fyne.Container
+	match   *widget.Select
+	filter  db.Filter // Being edited, not applied yet
+	columns []string
+
+	onApply func(f db.Filter)
+	onSave  func(f db.Filter)
+}
+
+func newFilterBar() *filterBar {
+	b := &filterBar{rules: container.NewVBox()}
+	b.match = widget.NewSelect([]string{matchAll, matchAny}, func(s string) {
+		b.filter.Any = s == matchAny
+	})
+	b.match.SetSelected(matchAll)
+
+	addBtn := widget.NewButton("+ Filter", func() {
+		b.filter.Rules = append(b.filter.Rules, db.FilterRule{Op: db.OpEqual})
+		b.render()
+	})
+	clearBtn := widget.NewButton("Clear", func() {
+		b.filter = db.Filter{}
+		b.render()
+		b.apply()
+	})
+	saveBtn := widget.NewButton("Save", func() {
+		if b.onSave != nil {
+			b.onSave(b.current())
+		}
+	})
+	applyBtn := widget.NewButton("Apply", b.apply)
+	applyBtn.Importance = widget.HighImportance
+
+	b.content = container.NewVBox(b.rules,
+		container.NewHBox(addBtn, b.match, layout.NewSpacer(), clearBtn, saveBtn, applyBtn))
+	return b
+}
+
+// current returns a copy of the filter being edited, which later edits
+// leave alone
+func (b *filterBar) current() db.Filter {
+	return db.Filter{Rules: slices.Clone(b.filter.Rules), Any: b.filter.Any}
+}
+
+func (b *filterBar) apply() {
+	if b.onApply != nil {
+		b.onApply(b.current())
+	}
+}
+
+// SetFilter replaces the filter being edited
+func (b *filterBar) SetFilter(f db.Filter) {
+	b.filter = db.Filter{Rules: slices.Clone(f.Rules), Any: f.Any}
+	b.render()
+}
+
+// SetColumns sets the columns rules can compare
+func (b *filterBar) SetColumns(columns []string) {
+	if slices.Equal(b.columns, columns) {
+		return
+	}
+	b.columns = slices.Clone(columns)
+	b.render()
+}
+
+func (b *filterBar) render() {
+	if b.filter.Any {
+		b.match.SetSelected(matchAny)
+	} else {
+		b.match.SetSelected(matchAll)
+	}
+	b.rules.RemoveAll()
+	for i := range b.filter.Rules {
+		b.rules.Add(b.ruleRow(i))
+	}
+}
+
+// ruleRow builds the widgets editing the i-th rule
+func (b *filterBar) ruleRow(i int) fyne.CanvasObject {
+	r := &b.filter.Rules[i]
+
+	// A saved rule may name a column the result doesn't have
+	columns := b.columns
+	if r.Column != "" && !slices.Contains(columns, r.Column) {
+		columns = append(slices.Clone(columns), r.Column)
+	}
+	column := widget.NewSelect(columns, func(c string) { r.Column = c })
+	column.PlaceHolder = "Column"
+	column.SetSelected(r.Column)
+
+	ops := make([]string, len(db.FilterOps))
+	for j, op := range db.FilterOps {
+		ops[j] = string(op)
+	}
+	op := widget.NewSelect(ops, nil)
+	op.SetSelected(string(r.Op))
+	// The value entries depend on the operator
+	op.OnChanged = func(s string) {
+		r.Op = db.FilterOp(s)
+		b.render()
+	}
+
+	value := widget.NewEntry()
+	value.SetText(r.Value)
+	value.OnChanged = func(s string) { r.Value = s }
+	value.OnSubmitted = func(string) { b.apply() }
+	var values fyne.CanvasObject = value
+	switch r.Op {
+	case db.OpIsNull:
+		value.Disable()
+	case db.OpIn:
+		value.SetPlaceHolder("value, value, …")
+	case db.OpLike:
+		value.SetPlaceHolder("pattern, % matches anything")
+	case db.OpBetween:
+		value.SetPlaceHolder("from")
+		upper := widget.NewEntry()
+		upper.SetPlaceHolder("to")
+		upper.SetText(r.Value2)
+		upper.OnChanged = func(s string) { r.Value2 = s }
+		upper.OnSubmitted = func(string) { b.apply() }
+		values = container.NewGridWithColumns(2, value, upper)
+	}
+
+	remove := widget.NewButton("✕", func() {
+		b.filter.Rules = slices.Delete(b.filter.Rules, i, i+1)
+		b.render()
+	})
+	return container.NewBorder(nil, nil, container.NewHBox(column, op), remove, values)
+}
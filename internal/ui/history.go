@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pn/kymar/internal/config"
+)
+
+// allConnections is the connection filter of the history showing every entry
+const allConnections = "All connections"
+
+// historyMatches reports whether e contains every word of search, ignoring
+// case, in its query, error or database
+func historyMatches(e config.HistoryEntry, search string) bool {
+	text := strings.ToLower(e.Query + "\n" + e.Error + "\n" + e.Database)
+	for _, word := range strings.Fields(strings.ToLower(search)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// historySummary describes how a history entry ran
+func historySummary(e config.HistoryEntry) string {
+	where := e.Connection
+	if e.Database != "" {
+		where += " / " + e.Database
+	}
+	outcome := fmt.Sprintf("%d row(s)", e.Rows)
+	if e.Error != "" {
+		outcome = "Error: " + e.Error
+	}
+	return fmt.Sprintf("%s · %s · %v · %s",
+		e.Time.Local().Format("2006-01-02 15:04:05"), where, e.Duration.Round(time.Millisecond), outcome)
+}
+
+// showHistory shows the statements run from the query editor, newest first,
+// starting with those of connection. onInsert adds the chosen query to the
+// editor; onRun runs it.
+func showHistory(w fyne.Window, connection string, onInsert, onRun func(query string)) {
+	entries, err := config.LoadHistory()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load the query history: %w", err), w)
+		return
+	}
+	slices.Reverse(entries)
+
+	connections := []string{allConnections}
+	for _, e := range entries {
+		if !slices.Contains(connections, e.Connection) {
+			connections = append(connections, e.Connection)
+		}
+	}
+
+	var shown []config.HistoryEntry
+	selected := -1
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search queries…")
+	connectionSelect := widget.NewSelect(connections, nil)
+
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject {
+			query := widget.NewLabel("")
+			query.TextStyle = fyne.TextStyle{Monospace: true}
+			query.Truncation = fyne.TextTruncateEllipsis
+			summary := widget.NewLabel("")
+			summary.Truncation = fyne.TextTruncateEllipsis
+			summary.Importance = widget.LowImportance
+			return container.NewVBox(query, summary)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			if id >= len(shown) {
+				return
+			}
+			e := shown[id]
+			labels := o.(*fyne.Container).Objects
+			// Queries show on one line
+			labels[0].(*widget.Label).SetText(strings.Join(strings.Fields(e.Query), " "))
+			summary := labels[1].(*widget.Label)
+			summary.SetText(historySummary(e))
+			if e.Error != "" {
+				summary.Importance = widget.DangerImportance
+			} else {
+				summary.Importance = widget.LowImportance
+			}
+			summary.Refresh()
+		},
+	)
+
+	preview := widget.NewLabel("")
+	preview.TextStyle = fyne.TextStyle{Monospace: true}
+	preview.Wrapping = fyne.TextWrapWord
+	preview.Selectable = true
+	previewScroll := container.NewVScroll(preview)
+	previewScroll.SetMinSize(fyne.NewSize(0, 120))
+
+	insertBtn := widget.NewButton("Insert into Editor", nil)
+	runBtn := widget.NewButton("Run", nil)
+	runBtn.Importance = widget.HighImportance
+	insertBtn.Disable()
+	runBtn.Disable()
+
+	update := func() {
+		shown = shown[:0]
+		for _, e := range entries {
+			if connectionSelect.Selected != allConnections && e.Connection != connectionSelect.Selected {
+				continue
+			}
+			if historyMatches(e, search.Text) {
+				shown = append(shown, e)
+			}
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		preview.SetText("")
+		insertBtn.Disable()
+		runBtn.Disable()
+	}
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= len(shown) {
+			return
+		}
+		selected = id
+		preview.SetText(shown[id].Query)
+		insertBtn.Enable()
+		runBtn.Enable()
+	}
+	search.OnChanged = func(string) { update() }
+	connectionSelect.OnChanged = func(string) { update() }
+	if slices.Contains(connections, connection) {
+		connectionSelect.SetSelected(connection)
+	} else {
+		connectionSelect.SetSelected(allConnections)
+	}
+
+	var d dialog.Dialog
+	insertBtn.OnTapped = func() {
+		if selected >= 0 {
+			d.Hide()
+			onInsert(shown[selected].Query)
+		}
+	}
+	runBtn.OnTapped = func() {
+		if selected >= 0 {
+			d.Hide()
+			onRun(shown[selected].Query)
+		}
+	}
+	closeBtn := widget.NewButton("Close", func() { d.Hide() })
+
+	content := container.NewBorder(
+		container.NewBorder(nil, nil, nil, connectionSelect, search),
+		container.NewVBox(previewScroll, container.NewHBox(layout.NewSpacer(), closeBtn, insertBtn, runBtn)),
+		nil, nil,
+		list,
+	)
+	d = dialog.NewCustomWithoutButtons(fmt.Sprintf("Query History (%d)", len(entries)), content, w)
+	d.Resize(fyne.NewSize(900, 600))
+	d.Show()
+}
@@ -1,21 +1,31 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/pn/kymar/internal/config"
 	"github.com/pn/kymar/internal/db"
+	"github.com/pn/kymar/internal/secrets"
+	"github.com/pn/kymar/internal/ssh"
 )
 
-// ShowLoginScreen displays the login/connection screen
-func ShowLoginScreen(w fyne.Window, onConnect func(db.ConnParams)) {
+// ShowLoginScreen displays the login/connection screen. Passwords of saved
+// connections go to store.
+func ShowLoginScreen(w fyne.Window, store secrets.Store, onConnect func(name string, params db.ConnParams, limits db.Limits)) {
 	// Load saved connections
 	cfg, err := config.Load()
 	if err != nil {
@@ -42,7 +52,7 @@ func ShowLoginScreen(w fyne.Window, onConnect func(db.ConnParams)) {
 	favoritesList.OnSelected = func(id widget.ListItemID) {
 		if id < len(cfg.Connections) {
 			conn := cfg.Connections[id]
-			onConnect(conn.Params)
+			onConnect(conn.Name, conn.Params, cfg.LimitsFor(conn))
 		}
 	}
 
@@ -50,25 +60,42 @@ func ShowLoginScreen(w fyne.Window, onConnect func(db.ConnParams)) {
 	quickConnectHeader := widget.NewLabel("QUICK CONNECT")
 	quickConnectHeader.TextStyle = fyne.TextStyle{Bold: true}
 
-	// Callback to refresh the connections list
+	// Callback to refresh the connections list. The tabs share cfg, so it is
+	// reloaded in place.
 	refreshConnections := func() {
-		cfg, _ = config.Load()
+		if fresh, err := config.Load(); err == nil {
+			*cfg = *fresh
+		}
 		favoritesList.Refresh()
 	}
 
+	connect := func(name, fallback string, p db.ConnParams, limits db.Limits, save bool) {
+		connName := strings.TrimSpace(name)
+		if connName == "" {
+			connName = fallback
+		}
+		if save {
+			storeConnection(w, store, cfg, config.SavedConnection{Name: connName, Params: p, Limits: limits}, refreshConnections)
+		}
+		onConnect(connName, p, limits.Or(cfg.DefaultLimits()))
+	}
+
 	// Left sidebar content
 	sidebar := container.NewVBox(
 		favoritesHeader,
 		container.NewScroll(favoritesList),
 		widget.NewSeparator(),
 		quickConnectHeader,
+		widget.NewSeparator(),
+		widget.NewButton("Default Limits…", func() { showDefaultLimitsDialog(w, cfg) }),
 	)
 
 	// Connection tabs (TCP/IP, Socket, SSH)
 	connectionTabs := container.NewAppTabs(
-		container.NewTabItem("TCP/IP", createTCPIPTab(w, onConnect, cfg, refreshConnections)),
-		container.NewTabItem("Socket", createSocketTab(w)),
-		container.NewTabItem("SSH", createSSHTab(w, onConnect, cfg, refreshConnections)),
+		container.NewTabItem("TCP/IP", createTCPIPTab(w, cfg, connect)),
+		container.NewTabItem("Socket", createSocketTab(w, cfg, connect)),
+		container.NewTabItem("SSH", createSSHTab(w, cfg, connect)),
+		container.NewTabItem("File", createFileTab(w, cfg, connect)),
 	)
 
 	// Main connection area with centered form
@@ -88,11 +115,35 @@ func ShowLoginScreen(w fyne.Window, onConnect func(db.ConnParams)) {
 	w.SetContent(mainLayout)
 }
 
-func createTCPIPTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Config, refreshConnections func()) *fyne.Container {
-	// Database type selector
-	dbType := widget.NewSelect([]string{"MySQL", "PostgreSQL"}, nil)
-	dbType.SetSelected("MySQL")
+// connectFunc connects with the settings of a connection form under name,
+// saving them first when save is set. Connections left unnamed are named
+// fallback, after where they lead.
+type connectFunc func(name, fallback string, p db.ConnParams, limits db.Limits, save bool)
+
+// storeConnection saves conn with its passwords moved to store. Unlocking the
+// vault may prompt, so the store is written off the UI goroutine.
+func storeConnection(w fyne.Window, store secrets.Store, cfg *config.Config, conn config.SavedConnection, refreshConnections func()) {
+	oldRef := ""
+	if old := cfg.GetConnection(conn.Name); old != nil {
+		oldRef = old.Params.SecretRef
+	}
+	go func() {
+		params, secretErr := config.StoreSecrets(store, conn.Name, conn.Params, oldRef)
+		conn.Params = params
+		fyne.Do(func() {
+			if err := cfg.AddConnection(conn); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			refreshConnections()
+			if secretErr != nil && !errors.Is(secretErr, secrets.ErrCancelled) {
+				dialog.ShowError(fmt.Errorf("connection saved without its password: %w", secretErr), w)
+			}
+		})
+	}()
+}
 
+func createTCPIPTab(w fyne.Window, cfg *config.Config, connect connectFunc) *fyne.Container {
 	// Connection form fields
 	name := widget.NewEntry()
 	name.SetPlaceHolder("My Connection")
@@ -112,35 +163,23 @@ func createTCPIPTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Co
 	port.SetText("3306")
 
 	saveConnection := widget.NewCheck("Save this connection", nil)
+	limitsForm, getLimits := newLimitsForm(db.Limits{}, cfg.DefaultLimits())
 
-	// Set up database type change callback
-	dbType.OnChanged = func(value string) {
-		if value == "MySQL" {
-			if port.Text == "5432" || port.Text == "" {
-				port.SetText("3306")
-			}
-			if username.Text == "postgres" {
-				username.SetText("root")
-			}
-		} else if value == "PostgreSQL" {
-			if port.Text == "3306" || port.Text == "" {
-				port.SetText("5432")
-			}
-			if username.Text == "root" {
-				username.SetText("postgres")
-			}
-		}
-	}
+	tlsForm, applyTLS := newTLSForm(w)
+
+	// Database type selector
+	dbType := newDBTypeSelect(serverDialects(), port, username)
 
 	// Connect button
 	connectBtn := widget.NewButton("Connect", func() {
-		selectedDBType := "mysql"
-		if dbType.Selected == "PostgreSQL" {
-			selectedDBType = "postgres"
+		dialect, err := db.DialectByDisplayName(dbType.Selected)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
 		}
 
 		p := db.ConnParams{
-			DBType: selectedDBType,
+			DBType: dialect.Name(),
 			Host:   strings.TrimSpace(host.Text),
 			User:   strings.TrimSpace(username.Text),
 			Pass:   password.Text,
@@ -148,27 +187,9 @@ func createTCPIPTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Co
 			UseSSH: false,
 		}
 		p.Port, _ = strconv.Atoi(strings.TrimSpace(port.Text))
+		applyTLS(&p)
 
-		// Save connection if checkbox is checked
-		if saveConnection.Checked {
-			connName := strings.TrimSpace(name.Text)
-			if connName == "" {
-				connName = p.Host + ":" + strconv.Itoa(p.Port)
-			}
-
-			savedConn := config.SavedConnection{
-				Name:   connName,
-				Params: p,
-			}
-
-			if err := cfg.AddConnection(savedConn); err != nil {
-				dialog.ShowError(err, w)
-			} else {
-				refreshConnections()
-			}
-		}
-
-		onConnect(p)
+		connect(name.Text, p.Host+":"+strconv.Itoa(p.Port), p, getLimits(), saveConnection.Checked)
 	})
 	connectBtn.Importance = widget.HighImportance
 
@@ -195,6 +216,8 @@ func createTCPIPTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Co
 		container.NewGridWithColumns(2,
 			widget.NewLabel("Port:"), port,
 		),
+		tlsForm,
+		widget.NewAccordion(widget.NewAccordionItem("Timeouts & Limits", limitsForm)),
 		widget.NewSeparator(),
 		saveConnection,
 		connectBtn,
@@ -210,10 +233,11 @@ func createTCPIPTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Co
 	)
 }
 
-func createSocketTab(w fyne.Window) *fyne.Container {
-	// Socket connection form (simplified for now)
+func createSocketTab(w fyne.Window, cfg *config.Config, connect connectFunc) *fyne.Container {
+	name := widget.NewEntry()
+	name.SetPlaceHolder("My Socket Connection")
+
 	socketPath := widget.NewEntry()
-	socketPath.SetText("/tmp/mysql.sock")
 
 	username := widget.NewEntry()
 	username.SetText("root")
@@ -222,12 +246,54 @@ func createSocketTab(w fyne.Window) *fyne.Container {
 
 	database := widget.NewEntry()
 
+	saveConnection := widget.NewCheck("Save this connection", nil)
+	limitsForm, getLimits := newLimitsForm(db.Limits{}, cfg.DefaultLimits())
+
+	// Database type selector, also swapping the default socket location
+	dialects := socketDialects()
+	dbType := newDBTypeSelect(dialects, nil, username)
+	onTypeChanged := dbType.OnChanged
+	dbType.OnChanged = func(value string) {
+		onTypeChanged(value)
+		d, err := db.DialectByDisplayName(value)
+		if err != nil {
+			return
+		}
+		for _, other := range dialects {
+			if socketPath.Text == "" || socketPath.Text == other.(db.SocketDialect).DefaultSocket() {
+				socketPath.SetText(d.(db.SocketDialect).DefaultSocket())
+				break
+			}
+		}
+	}
+	dbType.OnChanged(dbType.Selected)
+
 	connectBtn := widget.NewButton("Connect", func() {
-		dialog.ShowInformation("Socket Connection", "Socket connections not implemented yet", w)
+		dialect, err := db.DialectByDisplayName(dbType.Selected)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		p := db.ConnParams{
+			DBType: dialect.Name(),
+			Socket: strings.TrimSpace(socketPath.Text),
+			User:   strings.TrimSpace(username.Text),
+			Pass:   password.Text,
+			DB:     strings.TrimSpace(database.Text),
+		}
+
+		connect(name.Text, p.Socket, p, getLimits(), saveConnection.Checked)
 	})
 	connectBtn.Importance = widget.HighImportance
 
 	form := container.NewVBox(
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Type:"), dbType,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Name:"), name,
+		),
 		container.NewGridWithColumns(2,
 			widget.NewLabel("Socket:"), socketPath,
 		),
@@ -240,7 +306,9 @@ func createSocketTab(w fyne.Window) *fyne.Container {
 		container.NewGridWithColumns(2,
 			widget.NewLabel("Database:"), database,
 		),
+		widget.NewAccordion(widget.NewAccordionItem("Timeouts & Limits", limitsForm)),
 		widget.NewSeparator(),
+		saveConnection,
 		connectBtn,
 	)
 
@@ -253,11 +321,7 @@ func createSocketTab(w fyne.Window) *fyne.Container {
 	)
 }
 
-func createSSHTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Config, refreshConnections func()) *fyne.Container {
-	// Database type selector
-	dbType := widget.NewSelect([]string{"MySQL", "PostgreSQL"}, nil)
-	dbType.SetSelected("MySQL")
-
+func createSSHTab(w fyne.Window, cfg *config.Config, connect connectFunc) *fyne.Container {
 	// Connection name
 	name := widget.NewEntry()
 	name.SetPlaceHolder("My SSH Connection")
@@ -276,47 +340,101 @@ func createSSHTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Conf
 	port := widget.NewEntry()
 	port.SetText("3306")
 
-	// Set up database type change callback
-	dbType.OnChanged = func(value string) {
-		if value == "MySQL" {
-			if port.Text == "5432" || port.Text == "" {
-				port.SetText("3306")
-			}
-			if username.Text == "postgres" {
-				username.SetText("root")
-			}
-		} else if value == "PostgreSQL" {
-			if port.Text == "3306" || port.Text == "" {
-				port.SetText("5432")
-			}
-			if username.Text == "root" {
-				username.SetText("postgres")
-			}
-		}
-	}
+	tlsForm, applyTLS := newTLSForm(w)
+
+	// Database type selector
+	dbType := newDBTypeSelect(serverDialects(), port, username)
 
 	// SSH fields
 	sshHost := widget.NewEntry()
-	sshHost.SetPlaceHolder("ssh.example.com")
+	sshHost.SetPlaceHolder("ssh.example.com or ~/.ssh/config alias")
 
 	sshUser := widget.NewEntry()
 	sshUser.SetPlaceHolder("ec2-user")
 
 	sshPassword := widget.NewPasswordEntry()
+	sshPassword.SetPlaceHolder("Optional with key or agent")
 
 	sshPort := widget.NewEntry()
 	sshPort.SetText("22")
 
+	sshKeyFile := widget.NewEntry()
+	sshKeyFile.SetPlaceHolder("~/.ssh/id_ed25519")
+	sshKeyBrowse := newBrowseButton(w, sshKeyFile, ssh.ExpandHome("~/.ssh"), nil)
+
+	// Default to the agent when one is running
+	sshUseAgent := widget.NewCheck("Use SSH agent", nil)
+	sshUseAgent.SetChecked(os.Getenv("SSH_AUTH_SOCK") != "")
+
+	jumpEditor, jumpHosts := newJumpHostEditor(w)
+
+	// Resolve ~/.ssh/config aliases once typing the host pauses, off the UI
+	// goroutine. Fields are only filled while they still hold the previous
+	// automatic value.
+	sshConfigHint := widget.NewLabel("")
+	sshConfigHint.Wrapping = fyne.TextWrapWord
+	autoPort, autoUser, autoKey := sshPort.Text, sshUser.Text, sshKeyFile.Text
+	autoFill := func(e *widget.Entry, last *string, value string) {
+		if e.Text == *last {
+			e.SetText(value)
+			*last = value
+		}
+	}
+	applyHostConfig := func(hc ssh.HostConfig, err error) {
+		if err != nil {
+			sshConfigHint.SetText("~/.ssh/config not used: " + err.Error())
+			return
+		}
+
+		port := "22"
+		if hc.Port != 0 {
+			port = strconv.Itoa(hc.Port)
+		}
+		autoFill(sshPort, &autoPort, port)
+		autoFill(sshUser, &autoUser, hc.User)
+		autoFill(sshKeyFile, &autoKey, hc.IdentityFile)
+
+		var hint []string
+		if hc.HostName != "" {
+			hint = append(hint, "→ "+hc.HostName)
+		}
+		if hc.ProxyJump != "" {
+			hint = append(hint, "via "+hc.ProxyJump)
+		}
+		if len(hint) > 0 {
+			sshConfigHint.SetText("~/.ssh/config: " + strings.Join(hint, " "))
+		} else {
+			sshConfigHint.SetText("")
+		}
+	}
+	var resolveTimer *time.Timer
+	sshHost.OnChanged = func(alias string) {
+		if resolveTimer != nil {
+			resolveTimer.Stop()
+		}
+		resolveTimer = time.AfterFunc(400*time.Millisecond, func() {
+			hc, err := ssh.ResolveHost(strings.TrimSpace(alias))
+			fyne.Do(func() {
+				// A later keystroke has its own lookup
+				if sshHost.Text == alias {
+					applyHostConfig(hc, err)
+				}
+			})
+		})
+	}
+
 	saveConnection := widget.NewCheck("Save this connection", nil)
+	limitsForm, getLimits := newLimitsForm(db.Limits{}, cfg.DefaultLimits())
 
 	connectBtn := widget.NewButton("Connect", func() {
-		selectedDBType := "mysql"
-		if dbType.Selected == "PostgreSQL" {
-			selectedDBType = "postgres"
+		dialect, err := db.DialectByDisplayName(dbType.Selected)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
 		}
 
 		p := db.ConnParams{
-			DBType:  selectedDBType,
+			DBType:  dialect.Name(),
 			Host:    strings.TrimSpace(host.Text),
 			User:    strings.TrimSpace(username.Text),
 			Pass:    password.Text,
@@ -325,30 +443,16 @@ func createSSHTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Conf
 			SSHHost: strings.TrimSpace(sshHost.Text),
 			SSHUser: strings.TrimSpace(sshUser.Text),
 			SSHPass: sshPassword.Text,
+
+			SSHKeyFile:  strings.TrimSpace(sshKeyFile.Text),
+			SSHUseAgent: sshUseAgent.Checked,
+			SSHJumps:    jumpHosts(),
 		}
 		p.Port, _ = strconv.Atoi(strings.TrimSpace(port.Text))
+		applyTLS(&p)
 		p.SSHPort, _ = strconv.Atoi(strings.TrimSpace(sshPort.Text))
 
-		// Save connection if checkbox is checked
-		if saveConnection.Checked {
-			connName := strings.TrimSpace(name.Text)
-			if connName == "" {
-				connName = p.Host + " via SSH"
-			}
-
-			savedConn := config.SavedConnection{
-				Name:   connName,
-				Params: p,
-			}
-
-			if err := cfg.AddConnection(savedConn); err != nil {
-				dialog.ShowError(err, w)
-			} else {
-				refreshConnections()
-			}
-		}
-
-		onConnect(p)
+		connect(name.Text, p.Host+" via SSH", p, getLimits(), saveConnection.Checked)
 	})
 	connectBtn.Importance = widget.HighImportance
 
@@ -375,11 +479,13 @@ func createSSHTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Conf
 		container.NewGridWithColumns(2,
 			widget.NewLabel("Port:"), port,
 		),
+		tlsForm,
 		widget.NewSeparator(),
 		widget.NewLabel("SSH Tunnel"),
 		container.NewGridWithColumns(2,
 			widget.NewLabel("SSH Host:"), sshHost,
 		),
+		sshConfigHint,
 		container.NewGridWithColumns(2,
 			widget.NewLabel("SSH User:"), sshUser,
 		),
@@ -389,6 +495,16 @@ func createSSHTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Conf
 		container.NewGridWithColumns(2,
 			widget.NewLabel("SSH Port:"), sshPort,
 		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Private Key:"), container.NewBorder(nil, nil, nil, sshKeyBrowse, sshKeyFile),
+		),
+		container.NewGridWithColumns(2,
+			layout.NewSpacer(), sshUseAgent,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Jump Hosts:"), jumpEditor,
+		),
+		widget.NewAccordion(widget.NewAccordionItem("Timeouts & Limits", limitsForm)),
 		widget.NewSeparator(),
 		saveConnection,
 		connectBtn,
@@ -402,3 +518,384 @@ func createSSHTab(w fyne.Window, onConnect func(db.ConnParams), cfg *config.Conf
 		container.NewPadded(form),
 	)
 }
+
+func createFileTab(w fyne.Window, cfg *config.Config, connect connectFunc) *fyne.Container {
+	// Only file-based engines are offered here
+	var labels []string
+	for _, d := range db.Dialects() {
+		if _, isFile := d.(db.FileDialect); isFile {
+			labels = append(labels, d.DisplayName())
+		}
+	}
+
+	dbType := widget.NewSelect(labels, nil)
+	if len(labels) > 0 {
+		dbType.SetSelected(labels[0])
+	}
+
+	name := widget.NewEntry()
+	name.SetPlaceHolder("My Database File")
+
+	filePath := widget.NewEntry()
+	filePath.SetPlaceHolder("/path/to/database.db")
+
+	browseBtn := newBrowseButton(w, filePath, "", func() storage.FileFilter {
+		if d, err := db.DialectByDisplayName(dbType.Selected); err == nil {
+			return storage.NewExtensionFileFilter(d.(db.FileDialect).FileExtensions())
+		}
+		return nil
+	})
+
+	saveConnection := widget.NewCheck("Save this connection", nil)
+	limitsForm, getLimits := newLimitsForm(db.Limits{}, cfg.DefaultLimits())
+
+	connectBtn := widget.NewButton("Connect", func() {
+		dialect, err := db.DialectByDisplayName(dbType.Selected)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		p := db.ConnParams{
+			DBType: dialect.Name(),
+			File:   strings.TrimSpace(filePath.Text),
+		}
+		if p.File == "" {
+			dialog.ShowInformation("Missing file", "Choose a database file to open.", w)
+			return
+		}
+
+		connect(name.Text, filepath.Base(p.File), p, getLimits(), saveConnection.Checked)
+	})
+	connectBtn.Importance = widget.HighImportance
+
+	form := container.NewVBox(
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Type:"), dbType,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Name:"), name,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("File:"), container.NewBorder(nil, nil, nil, browseBtn, filePath),
+		),
+		widget.NewAccordion(widget.NewAccordionItem("Timeouts & Limits", limitsForm)),
+		widget.NewSeparator(),
+		saveConnection,
+		connectBtn,
+	)
+
+	return container.NewBorder(
+		layout.NewSpacer(),
+		layout.NewSpacer(),
+		layout.NewSpacer(),
+		layout.NewSpacer(),
+		container.NewPadded(form),
+	)
+}
+
+// newJumpHostEditor manages the ordered list of SSH jump hosts dialled before
+// the SSH host. It returns the widget and a getter for the current hops.
+func newJumpHostEditor(w fyne.Window) (fyne.CanvasObject, func() []ssh.Hop) {
+	var hops []ssh.Hop
+	rows := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		for i, hop := range hops {
+			idx := i
+			desc := fmt.Sprintf("%d. %s@%s", i+1, hop.Auth.User, hop.Addr())
+			removeBtn := widget.NewButton("✕", func() {
+				hops = append(hops[:idx], hops[idx+1:]...)
+				refresh()
+			})
+			removeBtn.Importance = widget.LowImportance
+			rows.Add(container.NewBorder(nil, nil, nil, removeBtn, widget.NewLabel(desc)))
+		}
+	}
+
+	addBtn := widget.NewButton("Add Jump Host…", func() {
+		host := widget.NewEntry()
+		host.SetPlaceHolder("bastion.example.com")
+		port := widget.NewEntry()
+		port.SetText("22")
+		user := widget.NewEntry()
+		password := widget.NewPasswordEntry()
+		password.SetPlaceHolder("Optional with key or agent")
+		keyFile := widget.NewEntry()
+		keyFile.SetPlaceHolder("~/.ssh/id_ed25519")
+		useAgent := widget.NewCheck("Use SSH agent", nil)
+		useAgent.SetChecked(os.Getenv("SSH_AUTH_SOCK") != "")
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("Host", host),
+			widget.NewFormItem("Port", port),
+			widget.NewFormItem("User", user),
+			widget.NewFormItem("Password", password),
+			widget.NewFormItem("Private Key", container.NewBorder(nil, nil, nil,
+				newBrowseButton(w, keyFile, ssh.ExpandHome("~/.ssh"), nil), keyFile)),
+			widget.NewFormItem("", useAgent),
+		}
+		d := dialog.NewForm("Add Jump Host", "Add", "Cancel", items, func(ok bool) {
+			if !ok || strings.TrimSpace(host.Text) == "" {
+				return
+			}
+			hop := ssh.Hop{
+				Host: strings.TrimSpace(host.Text),
+				Auth: ssh.Auth{
+					User:     strings.TrimSpace(user.Text),
+					Password: password.Text,
+					KeyFile:  strings.TrimSpace(keyFile.Text),
+					UseAgent: useAgent.Checked,
+				},
+			}
+			hop.Port, _ = strconv.Atoi(strings.TrimSpace(port.Text))
+			hops = append(hops, hop)
+			refresh()
+		}, w)
+		d.Resize(fyne.NewSize(480, 0))
+		d.Show()
+	})
+
+	return container.NewVBox(rows, addBtn), func() []ssh.Hop {
+		return append([]ssh.Hop(nil), hops...)
+	}
+}
+
+// newTLSForm builds the SSL mode and certificate fields shared by the TCP/IP
+// and SSH tabs. apply copies the chosen settings into connection params.
+func newTLSForm(w fyne.Window) (fyne.CanvasObject, func(p *db.ConnParams)) {
+	sslMode := widget.NewSelect(db.SSLModes, nil)
+	sslMode.SetSelected(db.SSLDisable)
+
+	pemFilter := func() storage.FileFilter {
+		return storage.NewExtensionFileFilter([]string{".pem", ".crt", ".cer", ".key"})
+	}
+	fileRow := func(label string) (*widget.Entry, fyne.CanvasObject) {
+		e := widget.NewEntry()
+		e.SetPlaceHolder("Optional")
+		return e, container.NewGridWithColumns(2,
+			widget.NewLabel(label), container.NewBorder(nil, nil, nil, newBrowseButton(w, e, "", pemFilter), e),
+		)
+	}
+	caCert, caRow := fileRow("CA Cert:")
+	clientCert, certRow := fileRow("Client Cert:")
+	clientKey, keyRow := fileRow("Client Key:")
+
+	// Certificate files only matter once TLS is on
+	files := container.NewVBox(caRow, certRow, keyRow)
+	files.Hide()
+	sslMode.OnChanged = func(mode string) {
+		if mode == db.SSLDisable {
+			files.Hide()
+		} else {
+			files.Show()
+		}
+	}
+
+	form := container.NewVBox(
+		container.NewGridWithColumns(2,
+			widget.NewLabel("SSL Mode:"), sslMode,
+		),
+		files,
+	)
+	apply := func(p *db.ConnParams) {
+		p.SSLMode = sslMode.Selected
+		if p.SSLMode == db.SSLDisable {
+			return
+		}
+		p.SSLCA = strings.TrimSpace(caCert.Text)
+		p.SSLCert = strings.TrimSpace(clientCert.Text)
+		p.SSLKey = strings.TrimSpace(clientKey.Text)
+	}
+	return form, apply
+}
+
+// newLimitsForm builds the timeout and limit fields. Empty fields are unset
+// and show the value of fallback; 0 means no limit. get returns the limits
+// entered.
+func newLimitsForm(initial, fallback db.Limits) (fyne.CanvasObject, func() db.Limits) {
+	field := func(value, def int) *widget.Entry {
+		e := widget.NewEntry()
+		e.Validator = validation.NewRegexp(`^\d*$`, "Enter a whole number")
+		switch {
+		case def == db.Unlimited:
+			e.SetPlaceHolder("Default: none")
+		default:
+			e.SetPlaceHolder("Default: " + strconv.Itoa(def))
+		}
+		switch {
+		case value == db.Unlimited:
+			e.SetText("0")
+		case value > 0:
+			e.SetText(strconv.Itoa(value))
+		}
+		return e
+	}
+	value := func(e *widget.Entry) int {
+		text := strings.TrimSpace(e.Text)
+		if text == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 0 {
+			return 0
+		}
+		if n == 0 {
+			return db.Unlimited
+		}
+		return n
+	}
+
+	connectTimeout := field(initial.ConnectTimeout, fallback.ConnectTimeout)
+	statementTimeout := field(initial.StatementTimeout, fallback.StatementTimeout)
+	metadataTimeout := field(initial.MetadataTimeout, fallback.MetadataTimeout)
+	maxRows := field(initial.MaxRows, fallback.MaxRows)
+	poolSize := field(initial.PoolSize, fallback.PoolSize)
+
+	hint := widget.NewLabel("Timeouts are in seconds. Leave a field empty for the default; 0 means no limit.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	form := container.NewVBox(
+		hint,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Connect Timeout:"), connectTimeout,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Statement Timeout:"), statementTimeout,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Metadata Timeout:"), metadataTimeout,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Max Rows:"), maxRows,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Pool Size:"), poolSize,
+		),
+	)
+	get := func() db.Limits {
+		l := db.Limits{
+			ConnectTimeout:   value(connectTimeout),
+			StatementTimeout: value(statementTimeout),
+			MetadataTimeout:  value(metadataTimeout),
+			MaxRows:          value(maxRows),
+			PoolSize:         value(poolSize),
+		}
+		if l.PoolSize == db.Unlimited {
+			l.PoolSize = 0 // The pool always has a size
+		}
+		return l
+	}
+	return form, get
+}
+
+// showDefaultLimitsDialog edits the limits of connections that don't set
+// their own
+func showDefaultLimitsDialog(w fyne.Window, cfg *config.Config) {
+	form, get := newLimitsForm(cfg.Defaults, db.DefaultLimits)
+	d := dialog.NewCustomConfirm("Default Timeouts & Limits", "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := cfg.SetDefaults(get()); err != nil {
+			dialog.ShowError(err, w)
+		}
+	}, w)
+	d.Resize(fyne.NewSize(460, 0))
+	d.Show()
+}
+
+// newBrowseButton opens a file picker and writes the chosen path into target.
+// startDir is used when target is empty; filter may be nil or return nil.
+func newBrowseButton(w fyne.Window, target *widget.Entry, startDir string, filter func() storage.FileFilter) *widget.Button {
+	return widget.NewButton("Browse…", func() {
+		picker := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if r == nil {
+				return // Cancelled
+			}
+			defer r.Close()
+			target.SetText(r.URI().Path())
+		}, w)
+
+		dir := startDir
+		if current := strings.TrimSpace(target.Text); current != "" {
+			dir = filepath.Dir(ssh.ExpandHome(current))
+		}
+		if dir != "" {
+			if lister, err := storage.ListerForURI(storage.NewFileURI(dir)); err == nil {
+				picker.SetLocation(lister)
+			}
+		}
+		if filter != nil {
+			if f := filter(); f != nil {
+				picker.SetFilter(f)
+			}
+		}
+		picker.Show()
+	})
+}
+
+// serverDialects returns the dialects that connect to a database server
+func serverDialects() []db.Dialect {
+	var out []db.Dialect
+	for _, d := range db.Dialects() {
+		if _, isFile := d.(db.FileDialect); !isFile {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// socketDialects returns the dialects that can connect over a Unix socket
+func socketDialects() []db.Dialect {
+	var out []db.Dialect
+	for _, d := range db.Dialects() {
+		if _, ok := d.(db.SocketDialect); ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// newDBTypeSelect builds a database type selector for dialects. Changing the
+// type swaps port and username when they still hold another engine's
+// defaults. port may be nil for forms without a port field.
+func newDBTypeSelect(dialects []db.Dialect, port, username *widget.Entry) *widget.Select {
+	labels := make([]string, len(dialects))
+	for i, d := range dialects {
+		labels[i] = d.DisplayName()
+	}
+
+	isDefault := func(value string, get func(db.Dialect) string) bool {
+		for _, d := range dialects {
+			if value == get(d) {
+				return true
+			}
+		}
+		return false
+	}
+
+	dbType := widget.NewSelect(labels, nil)
+	dbType.OnChanged = func(value string) {
+		d, err := db.DialectByDisplayName(value)
+		if err != nil {
+			return
+		}
+		if port != nil && (port.Text == "" || isDefault(port.Text, func(d db.Dialect) string { return strconv.Itoa(d.DefaultPort()) })) {
+			port.SetText(strconv.Itoa(d.DefaultPort()))
+		}
+		if isDefault(username.Text, db.Dialect.DefaultUser) {
+			username.SetText(d.DefaultUser())
+		}
+	}
+	if len(labels) > 0 {
+		dbType.SetSelected(labels[0])
+	}
+	return dbType
+}
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"image/color"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -16,138 +19,66 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/pn/kymar/internal/config"
 	"github.com/pn/kymar/internal/db"
 )
 
-// ShowMainInterface displays the main database query interface
-func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connParams db.ConnParams, onDisconnect func()) {
+// ShowMainInterface displays the main database query interface of the
+// connection named connName
+func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connName string, connParams db.ConnParams, limits db.Limits, onDisconnect func()) {
+	dialect, err := db.GetDialect(connParams.DBType)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	limits = limits.Or(db.DefaultLimits)
+
+	// Pin the editor and the sidebar to connections of their own, so session
+	// state set by the user's queries sticks
+	ctx, cancel := db.WithTimeout(context.Background(), limits.ConnectTimeout)
+	sess, err := db.OpenSession(ctx, dbh, dialect, db.Timeout(limits.StatementTimeout))
+	cancel()
+	if err != nil {
+		_ = dbh.Close()
+		_ = closer()
+		dialog.ShowError(fmt.Errorf("failed to open session: %w", err), w)
+		onDisconnect()
+		return
+	}
+
+	// The server enforces the statement timeout where it can. The client
+	// deadline comes a little later, so the server's error, which leaves the
+	// connection usable, wins the race.
+	queryTimeout := limits.StatementTimeout
+	if _, ok := dialect.(db.StatementTimeouter); ok && queryTimeout > 0 {
+		queryTimeout += 2
+	}
+
+	// Dialects that can switch databases list them first when none is selected
+	switcher, canSwitch := dialect.(db.DatabaseSwitcher)
+	showingDatabases := canSwitch && connParams.DB == ""
+
 	// Table list state
 	var tableNames []string
 
 	// Sort state tracking
 	var currentTable string
 	var sortColumn string
-	var sortDirection string // "ASC" or "DESC"
+	var sortDirection string        // "ASC" or "DESC"
+	var browseQuery string          // Last query generated to browse currentTable
+	var browseParams map[string]any // Its parameter values
+
+	// Pagination of currentTable
+	var tableKey []string     // Primary key, for keyset pagination
+	var tableRows int64 = -1  // Estimated row count, -1 if unknown
+	var page db.Page          // Page browsed
+	var pageOffset int        // Offset of its first row, estimated when keyset paging, -1 if unknown
+	var tableFilter db.Filter // Filter applied to currentTable
+	pageSize := 100
 
 	// Query editor
-	queryEditorInput := widget.NewMultiLineEntry()
-	queryEditorInput.SetPlaceHolder("-- Enter your SQL query here\n-- Example: SELECT * FROM users LIMIT 10;\n-- Tip: Use Cmd+Enter or click 'Run Query' to execute")
-
-	// Table model state
-	var headers []string     // Display headers with types (e.g., "id (BIGINT)")
-	var columnNames []string // Column names without types (for queries)
-	var rows [][]string
-	var selectedRow int = -1 // Track which row is selected (-1 means none)
-
-	// Results table
-	table := widget.NewTable(
-		func() (int, int) {
-			if len(headers) == 0 {
-				return 1, 1
-			}
-			return len(rows) + 1, len(headers)
-		},
-		func() fyne.CanvasObject {
-			// Create a container with a background and a label
-			bg := canvas.NewRectangle(color.Transparent)
-			lbl := widget.NewLabel("")
-			lbl.Wrapping = fyne.TextTruncate
-			return container.NewMax(bg, lbl)
-		},
-		func(id widget.TableCellID, o fyne.CanvasObject) {
-			c := o.(*fyne.Container)
-			bg := c.Objects[0].(*canvas.Rectangle)
-			lbl := c.Objects[1].(*widget.Label)
-
-			if id.Row == 0 {
-				// Header row styling
-				if id.Col < len(headers) {
-					headerText := headers[id.Col]
-					// Add sort indicator if this column is being sorted
-					// Compare against the actual column name, not the display header
-					if currentTable != "" && id.Col < len(columnNames) && sortColumn == columnNames[id.Col] {
-						if sortDirection == "ASC" {
-							headerText += " ▲"
-						} else {
-							headerText += " ▼"
-						}
-					}
-					lbl.SetText(headerText)
-				} else {
-					lbl.SetText("")
-				}
-				lbl.TextStyle = fyne.TextStyle{Monospace: true}
-				lbl.Alignment = fyne.TextAlignCenter
-				lbl.Wrapping = fyne.TextTruncate
-				bg.FillColor = color.Transparent
-				bg.Refresh()
-				return
-			}
-			// Data rows
-			rowIdx := id.Row - 1
-			if rowIdx < len(rows) && id.Col < len(rows[rowIdx]) {
-				lbl.TextStyle = fyne.TextStyle{Monospace: true}
-				lbl.Alignment = fyne.TextAlignLeading
-				lbl.Wrapping = fyne.TextTruncate
-				lbl.SetText(rows[rowIdx][id.Col])
-
-				// Highlight the entire row if this row is selected
-				if rowIdx == selectedRow {
-					// Use a vivid, prominent highlight color like TablePlus
-					bg.FillColor = color.RGBA{R: 0, G: 115, B: 230, A: 255} // Solid blue highlight
-					lbl.TextStyle = fyne.TextStyle{Monospace: true}
-				} else {
-					bg.FillColor = color.Transparent
-				}
-				bg.Refresh()
-			}
-		},
-	)
-
-	// Set the header row to be sticky (non-scrolling)
-	table.StickyRowCount = 1
-	table.StickyColumnCount = 0
-
-	// Set intelligent column widths based on content
-	setupTableColumns := func() {
-		if len(headers) > 0 {
-			for i, header := range headers {
-				// Calculate width based on header and content
-				minWidth := float32(100) // Minimum 100px
-				maxWidth := float32(300) // Maximum 300px for readability
-
-				// Base width on header length
-				headerWidth := float32(len(header) * 8) // ~8px per character
-
-				// Check first few rows for content width
-				contentWidth := headerWidth
-				checkRows := len(rows)
-				if checkRows > 10 {
-					checkRows = 10 // Only check first 10 rows for performance
-				}
-
-				for j := 0; j < checkRows; j++ {
-					if j < len(rows) && i < len(rows[j]) {
-						cellWidth := float32(len(rows[j][i]) * 7) // ~7px per character for data
-						if cellWidth > contentWidth {
-							contentWidth = cellWidth
-						}
-					}
-				}
-
-				// Set width with min/max bounds
-				width := contentWidth + 20 // Add padding
-				if width < minWidth {
-					width = minWidth
-				}
-				if width > maxWidth {
-					width = maxWidth
-				}
-
-				table.SetColumnWidth(i, width)
-			}
-		}
-	}
+	queryEditorInput := newSQLEditor()
+	queryEditorInput.SetPlaceHolder("-- Enter your SQL query here\n-- Example: SELECT * FROM users LIMIT 10;\n-- Tip: Cmd/Ctrl+Enter runs the statement under the cursor,\n-- Cmd/Ctrl+Alt+Enter the selection, Cmd/Ctrl+Shift+Enter or 'Run Query' everything")
 
 	// Table information widget
 	tableInformation := widget.NewLabel("TABLE INFORMATION\n\nNo table selected")
@@ -183,44 +114,12 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 		return result
 	}
 
-	// Helper function to get primary key column for a table
-	getPrimaryKeyColumn := func(tableName string) string {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	// Helper function to get the primary key columns of a table
+	primaryKey := func(tableName string) ([]string, error) {
+		ctx, cancel := db.WithTimeout(context.Background(), limits.MetadataTimeout)
 		defer cancel()
 
-		if connParams.DBType == "mysql" {
-			// Query for primary key in MySQL
-			query := `
-				SELECT COLUMN_NAME 
-				FROM information_schema.KEY_COLUMN_USAGE 
-				WHERE TABLE_SCHEMA = DATABASE() 
-				AND TABLE_NAME = ? 
-				AND CONSTRAINT_NAME = 'PRIMARY'
-				ORDER BY ORDINAL_POSITION
-				LIMIT 1
-			`
-			var pkColumn string
-			err := dbh.QueryRowContext(ctx, query, tableName).Scan(&pkColumn)
-			if err == nil {
-				return pkColumn
-			}
-		} else {
-			// Query for primary key in PostgreSQL
-			query := `
-				SELECT a.attname
-				FROM pg_index i
-				JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
-				WHERE i.indrelid = $1::regclass AND i.indisprimary
-				ORDER BY a.attnum
-				LIMIT 1
-			`
-			var pkColumn string
-			err := dbh.QueryRowContext(ctx, query, tableName).Scan(&pkColumn)
-			if err == nil {
-				return pkColumn
-			}
-		}
-		return ""
+		return dialect.PrimaryKey(ctx, sess.Meta(), tableName)
 	}
 
 	// Function to fetch and display table metadata
@@ -230,89 +129,43 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := db.WithTimeout(context.Background(), limits.MetadataTimeout)
 		defer cancel()
 
-		var infoText string
-
-		if connParams.DBType == "mysql" {
-			// Use information_schema for more reliable, version-independent queries
-			query := `
-				SELECT 
-					ENGINE,
-					TABLE_ROWS,
-					DATA_LENGTH + INDEX_LENGTH as TOTAL_SIZE,
-					TABLE_COLLATION,
-					AUTO_INCREMENT,
-					CREATE_TIME
-				FROM information_schema.TABLES
-				WHERE TABLE_SCHEMA = DATABASE()
-				AND TABLE_NAME = ?
-			`
-			row := dbh.QueryRowContext(ctx, query, tableName)
-
-			var engine, collation sql.NullString
-			var rows, totalSize, autoIncrement sql.NullInt64
-			var createTime sql.NullTime
-
-			err := row.Scan(&engine, &rows, &totalSize, &collation, &autoIncrement, &createTime)
-
-			if err != nil {
-				infoText = fmt.Sprintf("Error fetching info:\n%v", err)
-			} else {
-				sizeStr := formatBytes(totalSize.Int64)
-
-				// Extract encoding from collation (e.g., utf8mb4_unicode_ci -> utf8mb4)
-				encoding := "unknown"
-				if collation.Valid && collation.String != "" {
-					encoding = collation.String
-				}
+		info, err := dialect.TableInfo(ctx, sess.Meta(), tableName)
+		tableRows = -1
+		if err != nil {
+			tableInformation.SetText(fmt.Sprintf("Error fetching info:\n%v", err))
+			return
+		}
+		if info.Rows.Valid {
+			tableRows = info.Rows.Int64
+		}
 
-				// Build info text with bullet points
-				infoText = "TABLE INFORMATION\n\n"
-				if createTime.Valid {
-					infoText += fmt.Sprintf("• created: %s\n", createTime.Time.Format("01/02/2006, 15:04"))
-				}
-				if engine.Valid {
-					infoText += fmt.Sprintf("• engine: %s\n", engine.String)
-				}
-				if rows.Valid {
-					infoText += fmt.Sprintf("• rows: %s\n", formatNumber(rows.Int64))
-				}
-				infoText += fmt.Sprintf("• size: %s\n", sizeStr)
-				infoText += fmt.Sprintf("• encoding: %s\n", encoding)
-				if autoIncrement.Valid && autoIncrement.Int64 > 0 {
-					infoText += fmt.Sprintf("• auto_increment: %s", formatNumber(autoIncrement.Int64))
-				}
-			}
-		} else {
-			// PostgreSQL table info
-			query := `
-				SELECT 
-					pg_size_pretty(pg_total_relation_size(quote_ident($1)::regclass)) as size,
-					(SELECT count(*) FROM ` + tableName + `) as row_count,
-					obj_description(quote_ident($1)::regclass) as comment
-			`
-			row := dbh.QueryRowContext(ctx, query, tableName)
-
-			var size, comment sql.NullString
-			var rowCount sql.NullInt64
-
-			err := row.Scan(&size, &rowCount, &comment)
-			if err != nil {
-				infoText = fmt.Sprintf("Error fetching info:\n%v", err)
-			} else {
-				infoText = "TABLE INFORMATION\n\n"
-				if rowCount.Valid {
-					infoText += fmt.Sprintf("• rows: %s\n", formatNumber(rowCount.Int64))
-				}
-				if size.Valid {
-					infoText += fmt.Sprintf("• size: %s\n", size.String)
-				}
-				if comment.Valid && comment.String != "" {
-					infoText += fmt.Sprintf("\n%s", comment.String)
-				}
-			}
+		// Build info text with bullet points
+		infoText := "TABLE INFORMATION\n\n"
+		if info.Created.Valid {
+			infoText += fmt.Sprintf("• created: %s\n", info.Created.Time.Format("01/02/2006, 15:04"))
+		}
+		if info.Engine.Valid {
+			infoText += fmt.Sprintf("• engine: %s\n", info.Engine.String)
+		}
+		if info.Rows.Valid {
+			infoText += fmt.Sprintf("• rows: %s\n", formatNumber(info.Rows.Int64))
+		}
+		if info.Size.Valid {
+			infoText += fmt.Sprintf("• size: %s\n", formatBytes(info.Size.Int64))
+		} else if info.SizePretty.Valid {
+			infoText += fmt.Sprintf("• size: %s\n", info.SizePretty.String)
+		}
+		if info.Encoding.Valid {
+			infoText += fmt.Sprintf("• encoding: %s\n", info.Encoding.String)
+		}
+		if info.AutoIncrement.Valid && info.AutoIncrement.Int64 > 0 {
+			infoText += fmt.Sprintf("• auto_increment: %s", formatNumber(info.AutoIncrement.Int64))
+		}
+		if info.Comment.Valid && info.Comment.String != "" {
+			infoText += fmt.Sprintf("\n%s", info.Comment.String)
 		}
 
 		tableInformation.SetText(infoText)
@@ -321,7 +174,7 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 	// Left sidebar (like Sequel Pro)
 	var tablesHeader *widget.Label
 
-	if connParams.DBType == "mysql" && connParams.DB == "" {
+	if showingDatabases {
 		tablesHeader = widget.NewLabel("DATABASES")
 	} else {
 		tablesHeader = widget.NewLabel("TABLES")
@@ -366,48 +219,24 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 
 	// Fetch tables function - defined early so it can be used in callbacks
 	fetchTables := func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := db.WithTimeout(context.Background(), limits.MetadataTimeout)
 		defer cancel()
 
-		var queryStr string
-		if connParams.DBType == "mysql" {
-			// For MySQL, if no database is selected, show all databases instead
-			if connParams.DB == "" {
-				queryStr = "SHOW DATABASES"
-			} else {
-				queryStr = "SHOW TABLES"
-			}
-		} else { // PostgreSQL
-			queryStr = "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_type = 'BASE TABLE'"
+		var tablesList []string
+		var err error
+		if showingDatabases {
+			// No database selected yet, show all databases instead
+			tablesList, err = switcher.ListDatabases(ctx, sess.Meta())
+		} else {
+			tablesList, err = dialect.ListTables(ctx, sess.Meta())
 		}
-
-		tableRows, err := dbh.QueryContext(ctx, queryStr)
 		if err != nil {
-			fmt.Printf("Error fetching tables: %v\n", err)
 			tableNames = nil
-			if tableList != nil {
-				tableList.Refresh()
-			}
+			applyTableFilter()
+			dialog.ShowError(fmt.Errorf("failed to list tables: %w", err), w)
 			return
 		}
-		defer tableRows.Close()
-
-		var tablesList []string
-		for tableRows.Next() {
-			var tableName string
-			if err := tableRows.Scan(&tableName); err != nil {
-				fmt.Printf("Error scanning table name: %v\n", err)
-				continue
-			}
-
-			tablesList = append(tablesList, tableName)
-		}
 
-		if err := tableRows.Err(); err != nil {
-			fmt.Printf("Error iterating table rows: %v\n", err)
-		}
-
-		fmt.Printf("Found %d tables: %v\n", len(tablesList), tablesList)
 		tableNames = tablesList
 		applyTableFilter() // Apply current filter to new table list
 	}
@@ -416,142 +245,1048 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 	status := widget.NewLabel("🟢 Connected")
 	status.TextStyle = fyne.TextStyle{Monospace: true}
 
-	// Run query function - define early so it can be used in table selection callback
-	runQuery := func() {
-		if dbh == nil {
-			dialog.ShowInformation("Not connected", "Database connection lost.", w)
+	// Manual-commit transaction toolbar
+	pendingStatements := 0
+	txStatus := widget.NewLabel("")
+	txStatus.TextStyle = fyne.TextStyle{Monospace: true}
+	beginTxBtn := widget.NewButton("Begin Transaction", nil)
+	commitBtn := widget.NewButton("Commit", nil)
+	commitBtn.Importance = widget.SuccessImportance
+	rollbackBtn := widget.NewButton("Rollback", nil)
+	rollbackBtn.Importance = widget.DangerImportance
+
+	updateTxToolbar := func() {
+		if !sess.InTx() {
+			pendingStatements = 0
+			txStatus.Hide()
+			commitBtn.Hide()
+			rollbackBtn.Hide()
+			beginTxBtn.Show()
 			return
 		}
-		q := strings.TrimSpace(queryEditorInput.Text)
-		if q == "" {
+		txStatus.SetText(fmt.Sprintf("🟠 Transaction open, %d statement(s) pending", pendingStatements))
+		txStatus.Show()
+		commitBtn.Show()
+		rollbackBtn.Show()
+		beginTxBtn.Hide()
+	}
+	updateTxToolbar()
+
+	// Session state, only touched on the UI goroutine
+	reconnecting := false // A lost session connection is being replaced
+	closed := false       // Disconnected; results of queries still running are dropped
+
+	// showQueryError reports a failed query. A lost session connection is
+	// replaced in the background, and the user told that its state is gone.
+	showQueryError := func(err error) {
+		if !db.ConnLost(err) || reconnecting {
+			dialog.ShowError(err, w)
 			return
 		}
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		reconnecting = true
+		beginTxBtn.Disable()
+		commitBtn.Disable()
+		rollbackBtn.Disable()
+		status.SetText("⏳ Connection lost, reconnecting…")
+		go func() {
+			ctx, cancel := db.WithTimeout(context.Background(), limits.ConnectTimeout)
+			defer cancel()
+			reset, rerr := sess.Reopen(ctx)
+			fyne.Do(func() {
+				reconnecting = false
+				if closed {
+					return
+				}
+				beginTxBtn.Enable()
+				commitBtn.Enable()
+				rollbackBtn.Enable()
+				updateTxToolbar()
+				switch {
+				case rerr != nil:
+					status.SetText("🔴 Disconnected")
+					dialog.ShowError(fmt.Errorf("connection lost and could not be reopened: %w", rerr), w)
+				case reset:
+					status.SetText("🟢 Connected")
+					dialog.ShowError(fmt.Errorf("%w\n\nThe connection was reopened. Variables, open transactions "+
+						"and temporary tables of the old session are gone.", err), w)
+				default:
+					status.SetText("🟢 Connected")
+					dialog.ShowError(err, w)
+				}
+			})
+		}()
+	}
+
+	beginTxBtn.OnTapped = func() {
+		if err := sess.Begin(); err != nil {
+			showQueryError(err)
+			return
+		}
+		updateTxToolbar()
+		status.SetText("🟠 Transaction started; statements are not committed until you press Commit")
+	}
+	commitBtn.OnTapped = func() {
+		err := sess.Commit()
+		updateTxToolbar()
+		if err != nil {
+			showQueryError(err)
+			return
+		}
+		status.SetText("🟢 Connected | Transaction committed")
+	}
+	rollbackBtn.OnTapped = func() {
+		err := sess.Rollback()
+		updateTxToolbar()
+		if err != nil {
+			showQueryError(err)
+			return
+		}
+		status.SetText("🟢 Connected | Transaction rolled back")
+	}
+
+	// Let the sidebar follow databases switched with USE in the editor
+	syncDatabase := func() {
+		if !canSwitch {
+			return
+		}
+		ctx, cancel := db.WithTimeout(context.Background(), limits.MetadataTimeout)
 		defer cancel()
-		start := time.Now()
-		headers = nil
-		rows = rows[:0]
-		selectedRow = -1 // Reset selection when running a new query
-
-		// Decide exec vs query
-		lower := strings.ToLower(q)
-		if strings.HasPrefix(lower, "select") || strings.HasPrefix(lower, "show") || strings.HasPrefix(lower, "desc") {
-			r, err := dbh.QueryContext(ctx, q)
-			if err != nil {
-				dialog.ShowError(err, w)
-				return
+
+		name, err := sess.SyncDatabase(ctx)
+		if err != nil || name == "" || (name == connParams.DB && !showingDatabases) {
+			return
+		}
+		connParams.DB = name
+		showingDatabases = false
+		tablesHeader.SetText("TABLES")
+		fetchTables()
+	}
+
+	// Buttons
+	runBtn := widget.NewButton("▶ Run Query", nil)
+	runBtn.Importance = widget.HighImportance
+	stopBtn := widget.NewButton("■ Stop", nil)
+	stopBtn.Importance = widget.DangerImportance
+	stopBtn.Hide()
+	historyBtn := widget.NewButton("History", nil)
+
+	// Running query state, only touched on the UI goroutine
+	var stopQuery context.CancelFunc // nil when no query runs
+	var queryDone chan struct{}
+	stopRequested := false
+
+	setRunning := func(running bool) {
+		if running {
+			runBtn.Disable()
+			beginTxBtn.Disable()
+			commitBtn.Disable()
+			rollbackBtn.Disable()
+			stopBtn.Enable()
+			stopBtn.Show()
+			return
+		}
+		stopQuery, queryDone = nil, nil
+		runBtn.Enable()
+		beginTxBtn.Enable()
+		commitBtn.Enable()
+		rollbackBtn.Enable()
+		stopBtn.Hide()
+	}
+
+	// queryBusy tells the user when a query is still running on the session,
+	// or its connection is being reopened
+	queryBusy := func() bool {
+		if reconnecting {
+			dialog.ShowInformation("Reconnecting", "Wait for the lost connection to be reopened.", w)
+			return true
+		}
+		if stopQuery == nil {
+			return false
+		}
+		dialog.ShowInformation("Query running", "Wait for the running query to finish or stop it first.", w)
+		return true
+	}
+
+	// Results area: one tab per statement of the last run
+	resultTabs := container.NewAppTabs()
+	tabResults := map[*container.TabItem]*resultSet{}
+	var onCellSelected func(rs *resultSet, id widget.TableCellID) // Set below
+	var showCellMenu func(rs *resultSet, id widget.TableCellID, pos fyne.Position)
+
+	// addResult shows rs in a new results tab
+	addResult := func(rs *resultSet, title string) {
+		rs.newTable(func(col int) string {
+			// Add sort indicator if this column is being sorted
+			// Compare against the actual column name, not the display header
+			if currentTable == "" || col >= len(rs.columnNames) || sortColumn != rs.columnNames[col] {
+				return ""
+			}
+			if sortDirection == "ASC" {
+				return " ▲"
 			}
-			defer r.Close()
+			return " ▼"
+		})
+		rs.table.OnSelected = func(id widget.TableCellID) { onCellSelected(rs, id) }
+		rs.onMenu = func(id widget.TableCellID, pos fyne.Position) { showCellMenu(rs, id, pos) }
+
+		tab := container.NewTabItem(title, rs.table)
+		tabResults[tab] = rs
+		resultTabs.Append(tab)
+		if len(resultTabs.Items) == 1 {
+			resultTabs.Select(tab)
+		}
+	}
+	clearResults := func() {
+		clear(tabResults)
+		resultTabs.SetItems(nil)
+	}
+	activeResult := func() *resultSet {
+		if tab := resultTabs.Selected(); tab != nil {
+			return tabResults[tab]
+		}
+		return nil
+	}
 
-			// Get column types
-			colTypes, err := r.ColumnTypes()
-			if err != nil {
-				dialog.ShowError(err, w)
-				return
+	// Pending changes to browsed rows
+	addRowBtn := widget.NewButton("+ Row", nil)
+	duplicateRowBtn := widget.NewButton("Duplicate", nil)
+	deleteRowBtn := widget.NewButton("Delete", nil)
+	saveChangesBtn := widget.NewButton("Save Changes", nil)
+	saveChangesBtn.Importance = widget.HighImportance
+	discardChangesBtn := widget.NewButton("Discard", nil)
+	updateEditButtons := func() {
+		rs := activeResult()
+		rowButtons := []*widget.Button{addRowBtn, duplicateRowBtn, deleteRowBtn}
+		for _, b := range rowButtons {
+			if rs != nil && rs.source != "" {
+				b.Show()
+			} else {
+				b.Hide()
+			}
+		}
+		if rs != nil && rs.selectedRow >= 0 {
+			duplicateRowBtn.Enable()
+			deleteRowBtn.Enable()
+			if rs.deleted[rs.selectedRow] {
+				deleteRowBtn.SetText("Undelete")
+			} else {
+				deleteRowBtn.SetText("Delete")
 			}
+		} else {
+			duplicateRowBtn.Disable()
+			deleteRowBtn.Disable()
+		}
 
-			// Build headers with types and store plain column names
-			columnNames = make([]string, len(colTypes))
-			headers = make([]string, len(colTypes))
-			for i, col := range colTypes {
-				columnNames[i] = col.Name()
-				typeName := col.DatabaseTypeName()
-				headers[i] = fmt.Sprintf("%s (%s)", col.Name(), typeName)
+		if rs == nil || rs.pending() == 0 {
+			saveChangesBtn.Hide()
+			discardChangesBtn.Hide()
+			return
+		}
+		saveChangesBtn.SetText(fmt.Sprintf("Save Changes (%d)", rs.pending()))
+		saveChangesBtn.Show()
+		discardChangesBtn.Show()
+	}
+	updateEditButtons()
+
+	// Pager of a browsed table
+	firstPageBtn := widget.NewButton("« First", nil)
+	prevPageBtn := widget.NewButton("‹ Prev", nil)
+	nextPageBtn := widget.NewButton("Next ›", nil)
+	lastPageBtn := widget.NewButton("Last »", nil)
+	pageLabel := widget.NewLabel("")
+	pageSizeSelect := widget.NewSelect([]string{"50", "100", "200", "500", "1000"}, nil)
+	pageSizeSelect.SetSelected(strconv.Itoa(pageSize))
+	pager := container.NewHBox(firstPageBtn, prevPageBtn, pageLabel, nextPageBtn, lastPageBtn,
+		layout.NewSpacer(), widget.NewLabel("Rows per page"), pageSizeSelect)
+	filters := newFilterBar()
+
+	// knownRows is the estimated number of rows browsed, -1 if unknown.
+	// Filtered tables count as unknown, since only the whole table's is.
+	knownRows := func() int64 {
+		if len(tableFilter.Active()) > 0 {
+			return -1
+		}
+		return tableRows
+	}
+
+	// keyset reports whether currentTable is paged by its primary key rather
+	// than by OFFSET, which needs the table sorted by that key
+	keyset := func() bool {
+		return len(tableKey) > 0 && (sortColumn == "" || sortColumn == tableKey[0])
+	}
+	// pageRows counts the rows read for the page, new rows left out
+	pageRows := func(rs *resultSet) int {
+		return len(rs.rows) - len(rs.added)
+	}
+	// pageStart estimates the offset of the first of the n rows of the page,
+	// -1 if unknown
+	pageStart := func(n int) int {
+		switch {
+		case page.Before && n < page.Limit:
+			return 0 // Nothing before
+		case page.Last && knownRows() >= 0:
+			return max(0, int(knownRows())-n)
+		case page.Last:
+			return -1
+		}
+		return pageOffset
+	}
+	// keyOf returns the primary key values of row as the driver returned
+	// them, or nil if the result lacks a key column
+	keyOf := func(rs *resultSet, row int) []any {
+		values := make([]any, len(tableKey))
+		for i, col := range tableKey {
+			j := slices.Index(rs.columnNames, col)
+			if j < 0 {
+				return nil
 			}
+			values[i] = rs.values[row][j]
+		}
+		return values
+	}
+	// updateBrowseBars shows the pager and the filter bar while the active
+	// result is a page of currentTable
+	updateBrowseBars := func() {
+		rs := activeResult()
+		if rs == nil || rs.source == "" || rs.source != currentTable {
+			pager.Hide()
+			filters.content.Hide()
+			return
+		}
+		if len(rs.columnNames) > 0 {
+			filters.SetColumns(rs.columnNames)
+		}
+		filters.content.Show()
 
-			vals := make([]sql.RawBytes, len(colTypes))
-			scanArgs := make([]any, len(colTypes))
-			for i := range vals {
-				scanArgs[i] = &vals[i]
+		n := pageRows(rs)
+		start := pageStart(n)
+		atStart := (len(page.Key) == 0 && page.Offset == 0 && !page.Last) || (page.Last && n < page.Limit)
+		if page.Before {
+			atStart = n < page.Limit
+		}
+		atEnd := page.Last || (!page.Before && n < page.Limit)
+		setEnabled := func(b *widget.Button, on bool) {
+			if on {
+				b.Enable()
+			} else {
+				b.Disable()
 			}
-			count := 0
-			for r.Next() {
-				if err := r.Scan(scanArgs...); err != nil {
-					dialog.ShowError(err, w)
+		}
+		setEnabled(firstPageBtn, !atStart)
+		setEnabled(prevPageBtn, !atStart || n == 0)
+		setEnabled(nextPageBtn, !atEnd)
+		setEnabled(lastPageBtn, !atEnd && (keyset() || knownRows() >= 0))
+
+		switch {
+		case n == 0:
+			pageLabel.SetText("No rows")
+		case start < 0:
+			pageLabel.SetText(fmt.Sprintf("%s row(s)", formatNumber(int64(n))))
+		case knownRows() >= 0:
+			total := max(knownRows(), int64(start+n))
+			pageLabel.SetText(fmt.Sprintf("rows %s–%s of ~%s",
+				formatNumber(int64(start+1)), formatNumber(int64(start+n)), formatNumber(total)))
+		default:
+			pageLabel.SetText(fmt.Sprintf("rows %s–%s",
+				formatNumber(int64(start+1)), formatNumber(int64(start+n))))
+		}
+		pager.Show()
+	}
+	updateBrowseBars()
+	resultTabs.OnSelected = func(*container.TabItem) {
+		updateEditButtons()
+		updateBrowseBars()
+	}
+
+	// pendingEdits counts the unsaved changes of all results
+	pendingEdits := func() int {
+		n := 0
+		for _, rs := range tabResults {
+			n += rs.pending()
+		}
+		return n
+	}
+	addResult(newResultSet(), "Result")
+
+	// browsing reports whether stmts is the query browsing currentTable
+	browsing := func(stmts []db.Statement) bool {
+		browse := db.SplitScript(browseQuery, dialect.Syntax())
+		return len(stmts) == 1 && len(browse) == 1 && browse[0].Text == stmts[0].Text
+	}
+
+	// Error handling for scripts
+	const (
+		stopOnError     = "Stop on error"
+		continueOnError = "Continue on error"
+	)
+	onErrorSelect := widget.NewSelect([]string{stopOnError, continueOnError}, nil)
+	onErrorSelect.SetSelected(stopOnError)
+
+	// remember records a statement run from the editor in the query history.
+	// The history is a convenience, so failing to write it is not reported.
+	remember := func(query, database string, start time.Time, rows int64, err error) {
+		e := config.HistoryEntry{
+			Query:      query,
+			Connection: connName,
+			Database:   database,
+			Time:       start,
+			Duration:   time.Since(start),
+			Rows:       rows,
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		_ = config.AppendHistory(e)
+	}
+
+	// runScript runs stmts one after the other in the background, each with
+	// its own results tab, binding values to their parameters. A single
+	// statement reports errors in a dialog; scripts report them in the
+	// statement's tab.
+	runScript := func(stmts []db.Statement, values map[string]any) {
+		if len(stmts) == 0 || queryBusy() {
+			return
+		}
+		script, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		stopQuery, queryDone, stopRequested = cancel, done, false
+		setRunning(true)
+		clearResults()
+		updateEditButtons()
+
+		keepGoing := onErrorSelect.Selected == continueOnError
+		syntax := dialect.Syntax()
+
+		// Rows of a table opened from the sidebar can be edited
+		source := ""
+		if browsing(stmts) {
+			source = currentTable
+		}
+		// Statements from the editor go to the history, generated browse
+		// queries don't
+		record := source == ""
+		database := connParams.DB
+		queryer, inTx := sess.Queryer(), sess.InTx()
+		start := time.Now()
+
+		// Show elapsed time and the rows fetched so far while the script runs
+		var fetched atomic.Int64
+		var current atomic.Int32
+		status.SetText("⏳ Running…")
+		go func() {
+			ticker := time.NewTicker(200 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
 					return
+				case <-ticker.C:
+					elapsed, n := time.Since(start).Round(100*time.Millisecond), fetched.Load()
+					text := fmt.Sprintf("⏳ Running… %v | %s row(s)", elapsed, formatNumber(n))
+					if len(stmts) > 1 {
+						text = fmt.Sprintf("⏳ Running statement %d of %d… %v | %s row(s)",
+							current.Load(), len(stmts), elapsed, formatNumber(n))
+					}
+					fyne.Do(func() {
+						if queryDone == done && !stopRequested {
+							status.SetText(text)
+						}
+					})
+				}
+			}
+		}()
+
+		go func() {
+			defer close(done)
+			defer cancel()
+
+			var (
+				ran, failed int
+				firstErr    error
+				lastResult  *resultSet
+				truncated   bool
+				switchedDB  bool
+				message     string // Outcome of a single statement without rows
+			)
+			for i, st := range stmts {
+				if script.Err() != nil {
+					break
+				}
+				current.Store(int32(i + 1))
+
+				title := "Result"
+				if len(stmts) > 1 {
+					title = fmt.Sprintf("Result %d", i+1)
 				}
-				out := make([]string, len(colTypes))
-				for i, v := range vals {
-					if v == nil {
-						out[i] = "NULL"
+				rs := newResultSet()
+				rs.source = source
+				lastResult = rs
+				fyne.Do(func() { addResult(rs, title) })
+
+				query, args := db.BindParameters(st.Text, syntax, db.StatementValues(stmts, i, syntax, values))
+				ctx, ctxCancel := db.WithTimeout(script, queryTimeout)
+				stmtStart, fetchedBefore := time.Now(), fetched.Load()
+				var rows int64
+				var err error
+				// Decide exec vs query
+				if db.ReturnsRows(st.Text, syntax) {
+					// Further result sets, e.g. of a CALL, get tabs of their own
+					set := 1
+					more := func() *resultSet {
+						set++
+						next, nextTitle := newResultSet(), fmt.Sprintf("%s (%d)", title, set)
+						lastResult = next
+						fyne.Do(func() { addResult(next, nextTitle) })
+						return next
+					}
+					// A cut result ends the query rather than reading the rest.
+					// Inside a transaction the rest is read instead, since a
+					// cancelled statement aborts the transaction on PostgreSQL.
+					var stop func()
+					if !inTx {
+						stop = func() {
+							cancelCtx, cancelDone := context.WithTimeout(context.Background(), 5*time.Second)
+							defer cancelDone()
+							// As with the Stop button, a server-side cancel keeps
+							// the connection where cutting the client off may not
+							if ok, err := sess.CancelQuery(cancelCtx); !ok || err != nil {
+								ctxCancel()
+							}
+						}
+					}
+					var cut bool
+					cut, err = streamRows(ctx, queryer, query, args, rs, more, limits.MaxRows, stop, &fetched)
+					truncated = truncated || cut
+					rows = fetched.Load() - fetchedBefore
+				} else {
+					var res sql.Result
+					res, err = queryer.ExecContext(ctx, query, args...)
+					if err == nil {
+						affected, _ := res.RowsAffected()
+						rows = affected
+						message = fmt.Sprintf("OK, %d row(s) affected", affected)
+						fyne.Do(func() {
+							rs.setMessage(message)
+							rs.refresh()
+							rs.setupColumns()
+						})
+						switchedDB = switchedDB || db.Keyword(st.Text, syntax) == "USE"
+					}
+				}
+				ctxCancel()
+				ran++
+				if inTx && record && err == nil {
+					// Queries from the editor count too: they may write, with
+					// RETURNING, FOR UPDATE or CALL
+					fyne.Do(func() {
+						pendingStatements++
+						updateTxToolbar()
+					})
+				}
+				if record {
+					remember(st.Text, database, stmtStart, rows, err)
+				}
+
+				if err != nil {
+					failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+					if len(stmts) > 1 {
+						msg, failedResult := "Error: "+err.Error(), lastResult
+						fyne.Do(func() {
+							failedResult.setMessage(msg)
+							failedResult.refresh()
+							failedResult.setupColumns()
+						})
+					}
+					if !keepGoing || db.ConnLost(err) || script.Err() != nil {
+						break
+					}
+				}
+			}
+
+			elapsed := time.Since(start)
+			fyne.Do(func() {
+				if closed {
+					return
+				}
+				setRunning(false)
+				updateBrowseBars()
+				if switchedDB {
+					syncDatabase()
+				}
+				if lastResult != nil && len(resultTabs.Items) > 1 && failed > 0 && !keepGoing {
+					resultTabs.SelectIndex(len(resultTabs.Items) - 1) // The failed statement
+				}
+
+				switch {
+				case stopRequested && (firstErr == nil || !db.ConnLost(firstErr)):
+					if len(stmts) == 1 {
+						status.SetText(fmt.Sprintf("🟡 Connected | Query cancelled after %d row(s)", fetched.Load()))
 					} else {
-						out[i] = string(v)
+						status.SetText(fmt.Sprintf("🟡 Connected | Script cancelled after %d of %d statement(s)", ran, len(stmts)))
 					}
+				case len(stmts) == 1 && firstErr != nil:
+					showQueryError(firstErr)
+				case firstErr != nil && db.ConnLost(firstErr):
+					showQueryError(firstErr)
+				case failed > 0:
+					status.SetText(fmt.Sprintf("🔴 Connected | %d of %d statement(s) ran, %d failed, in %v",
+						ran, len(stmts), failed, elapsed))
+				case len(stmts) > 1:
+					status.SetText(fmt.Sprintf("🟢 Connected | %d statement(s) in %v", ran, elapsed))
+				case message != "":
+					status.SetText(fmt.Sprintf("🟢 Connected | Done in %v", elapsed))
+				case truncated:
+					status.SetText(fmt.Sprintf("🟢 Connected | first %d row(s) in %v (max rows reached)", fetched.Load(), elapsed))
+				default:
+					status.SetText(fmt.Sprintf("🟢 Connected | %d row(s) in %v", fetched.Load(), elapsed))
+				}
+			})
+		}()
+	}
+
+	// discardingEdits calls then once the unsaved changes to the results, if
+	// any, are discarded after asking. If the user keeps them, cancelled is
+	// called instead, when not nil.
+	discardingEdits := func(then, cancelled func()) {
+		n := pendingEdits()
+		if n == 0 {
+			then()
+			return
+		}
+		dialog.ShowConfirm("Unsaved Changes",
+			fmt.Sprintf("The results have %d unsaved change(s). Discard them?", n),
+			func(ok bool) {
+				if !ok {
+					if cancelled != nil {
+						cancelled()
+					}
+					return
 				}
-				rows = append(rows, out)
-				count++
-				if count%200 == 0 {
-					table.Refresh()
+				for _, rs := range tabResults {
+					rs.discardEdits()
 				}
+				then()
+			}, w)
+	}
+
+	// runStatements asks for the values of query parameters, unless given
+	// values, and runs stmts. The browse query reuses the values of its page.
+	runStatements := func(stmts []db.Statement, values map[string]any) {
+		if len(stmts) == 0 || queryBusy() {
+			return
+		}
+		if values == nil && browsing(stmts) {
+			values = browseParams
+		}
+		discardingEdits(func() {
+			if values != nil {
+				runScript(stmts, values)
+				return
 			}
-			if err := r.Err(); err != nil {
-				dialog.ShowError(err, w)
+			names := db.ScriptParameters(stmts, dialect.Syntax())
+			if len(names) == 0 {
+				runScript(stmts, nil)
 				return
 			}
-			table.Refresh()
-			setupTableColumns()
-			status.SetText(fmt.Sprintf("🟢 Connected | %d row(s) in %v", len(rows), time.Since(start)))
+			showParamsDialog(w, names, func(values map[string]any) {
+				runScript(stmts, values)
+			})
+		}, nil)
+	}
+
+	// browse runs the query of page p of currentTable, whose first row is
+	// about the offset-th
+	browse := func(p db.Page, offset int) {
+		if queryBusy() {
 			return
 		}
-		res, err := dbh.ExecContext(ctx, q)
+		discardingEdits(func() {
+			page, pageOffset = p, offset
+			browseQuery, browseParams = dialect.SelectQuery(p), p.Params()
+			queryEditorInput.SetText(browseQuery)
+			runScript(db.SplitScript(browseQuery, dialect.Syntax()), browseParams)
+		}, nil)
+	}
+
+	// firstPage describes the first page of currentTable in its current sort
+	firstPage := func() db.Page {
+		p := db.Page{Table: currentTable, Direction: sortDirection, Limit: pageSize, Filter: tableFilter}
+		if keyset() {
+			p.OrderBy = tableKey
+		} else if sortColumn != "" {
+			p.OrderBy = []string{sortColumn}
+		}
+		return p
+	}
+	lastPage := func() {
+		p := firstPage()
+		if keyset() {
+			p.Last = true
+			browse(p, 0)
+			return
+		}
+		if knownRows() < 0 {
+			return
+		}
+		p.Offset = max(0, int(knownRows()-1)/pageSize*pageSize)
+		browse(p, p.Offset)
+	}
+	firstPageBtn.OnTapped = func() { browse(firstPage(), 0) }
+	nextPageBtn.OnTapped = func() {
+		rs := activeResult()
+		if rs == nil || pageRows(rs) == 0 {
+			return
+		}
+		n := pageRows(rs)
+		p, next := firstPage(), -1
+		if start := pageStart(n); start >= 0 {
+			next = start + n
+		}
+		if keyset() {
+			p.Key = keyOf(rs, n-1)
+		}
+		if p.Key == nil {
+			p.Offset = max(0, next)
+		}
+		browse(p, next)
+	}
+	prevPageBtn.OnTapped = func() {
+		rs := activeResult()
+		if rs == nil {
+			return
+		}
+		n := pageRows(rs)
+		if n == 0 {
+			// Past the end, e.g. after rows were deleted
+			lastPage()
+			return
+		}
+		p, prev := firstPage(), -1
+		if start := pageStart(n); start >= 0 {
+			prev = max(0, start-pageSize)
+		}
+		if keyset() {
+			p.Key, p.Before = keyOf(rs, 0), true
+		}
+		if p.Key == nil {
+			p.Before, p.Offset = false, max(0, prev)
+		}
+		browse(p, prev)
+	}
+	lastPageBtn.OnTapped = lastPage
+
+	// Filters apply from the first page and are saved per table
+	filters.onApply = func(f db.Filter) {
+		tableFilter = f
+		browse(firstPage(), 0)
+	}
+	filters.onSave = func(f db.Filter) {
+		cfg, err := config.Load()
+		if err == nil {
+			err = cfg.SetFilter(config.FilterKey(connParams, currentTable), f)
+		}
 		if err != nil {
-			dialog.ShowError(err, w)
+			dialog.ShowError(fmt.Errorf("failed to save the filter of %s: %w", currentTable, err), w)
 			return
 		}
-		affected, _ := res.RowsAffected()
-		headers = []string{"Result"}
-		rows = [][]string{{fmt.Sprintf("OK, %d row(s) affected", affected)}}
-		table.Refresh()
-		setupTableColumns()
-		status.SetText(fmt.Sprintf("🟢 Connected | Done in %v", time.Since(start)))
+		status.SetText(fmt.Sprintf("Saved the filter of %s", currentTable))
+	}
+	// savedFilter returns the filter saved for table, if any
+	savedFilter := func(table string) db.Filter {
+		cfg, err := config.Load()
+		if err != nil {
+			return db.Filter{}
+		}
+		return cfg.Filters[config.FilterKey(connParams, table)]
+	}
+	pageSizeSelect.OnChanged = func(size string) {
+		pageSize, _ = strconv.Atoi(size)
+		if rs := activeResult(); rs != nil && rs.source != "" && rs.source == currentTable {
+			browse(firstPage(), 0)
+		}
 	}
 
-	// Make column headers clickable for sorting (defined after run function)
-	table.OnSelected = func(id widget.TableCellID) {
-		if id.Row == 0 && currentTable != "" && id.Col < len(columnNames) {
-			// Clicked on a header - toggle sort
-			clickedColumn := columnNames[id.Col]
-
-			if sortColumn == clickedColumn {
-				// Toggle direction
-				if sortDirection == "ASC" {
-					sortDirection = "DESC"
-				} else {
-					sortDirection = "ASC"
+	// Run query function - define early so it can be used in table selection callback.
+	// Runs every statement in the editor.
+	runQuery := func() {
+		runStatements(db.SplitScript(queryEditorInput.Text, dialect.Syntax()), nil)
+	}
+
+	// runCurrent runs the statement under the cursor
+	runCurrent := func() {
+		stmts := db.SplitScript(queryEditorInput.Text, dialect.Syntax())
+		if st, ok := db.StatementAt(stmts, queryEditorInput.CursorOffset()); ok {
+			runStatements([]db.Statement{st}, nil)
+		}
+	}
+
+	// runSelection runs the statements of the selected text
+	runSelection := func() {
+		sel := queryEditorInput.SelectedText()
+		if strings.TrimSpace(sel) == "" {
+			status.SetText("Select the statements to run first")
+			return
+		}
+		runStatements(db.SplitScript(sel, dialect.Syntax()), nil)
+	}
+
+	// Stop asks the server to abort the statement, then gives up on it client
+	// side if that fails or takes too long
+	stopBtn.OnTapped = func() {
+		if stopQuery == nil {
+			return
+		}
+		stopRequested = true
+		stopBtn.Disable()
+		status.SetText("🟡 Cancelling…")
+
+		cancel, done := stopQuery, queryDone
+		go func() {
+			ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer ctxCancel()
+
+			if ok, err := sess.CancelQuery(ctx); ok && err == nil {
+				// Cutting the client off can cost the connection and its
+				// session state with some drivers, so wait for the server
+				select {
+				case <-done:
+					return
+				case <-time.After(3 * time.Second):
 				}
-			} else {
-				// New column - default to ASC
-				sortColumn = clickedColumn
-				sortDirection = "ASC"
 			}
+			cancel()
+		}()
+	}
+
+	// makeEditable looks up the primary key of the table rs was browsed from.
+	// Tables without one are read-only, since their rows can't be told apart.
+	makeEditable := func(rs *resultSet) bool {
+		if rs.source == "" {
+			status.SetText("Only rows of a table opened from the sidebar can be edited")
+			return false
+		}
+		if queryBusy() {
+			return false
+		}
+		if rs.editable() {
+			return true
+		}
+		pk, err := primaryKey(rs.source)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to look up the primary key of %s: %w", rs.source, err), w)
+			return false
+		}
+		if len(pk) == 0 {
+			dialog.ShowInformation("Read-only Table",
+				fmt.Sprintf("%s has no primary key, so its rows cannot be edited.", rs.source), w)
+			return false
+		}
+		var key []int
+		for _, col := range pk {
+			i := slices.Index(rs.columnNames, col)
+			if i < 0 {
+				dialog.ShowInformation("Read-only Result",
+					fmt.Sprintf("The result lacks the primary key column %s of %s.", col, rs.source), w)
+				return false
+			}
+			key = append(key, i)
+		}
+		rs.key = key
+		return true
+	}
 
-			// Regenerate and run the query with ORDER BY
-			var sqlQuery string
-			if connParams.DBType == "mysql" {
-				sqlQuery = fmt.Sprintf("SELECT * FROM `%s` ORDER BY `%s` %s LIMIT 100;",
-					currentTable, sortColumn, sortDirection)
-			} else { // PostgreSQL
-				sqlQuery = fmt.Sprintf("SELECT * FROM \"%s\" ORDER BY \"%s\" %s LIMIT 100;",
-					currentTable, sortColumn, sortDirection)
+	// Editing actions on a result
+	editCell := func(rs *resultSet, c cell) {
+		if makeEditable(rs) {
+			showCellEditor(w, rs, c, updateEditButtons)
+		}
+	}
+	addRow := func(rs *resultSet) {
+		if !makeEditable(rs) {
+			return
+		}
+		rs.selectedRow = rs.addRow()
+		rs.refresh()
+		rs.table.ScrollToBottom()
+		updateEditButtons()
+	}
+	duplicateRow := func(rs *resultSet) {
+		if rs.selectedRow < 0 || !makeEditable(rs) {
+			return
+		}
+		rs.selectedRow = rs.duplicateRow(rs.selectedRow)
+		rs.refresh()
+		rs.table.ScrollToBottom()
+		updateEditButtons()
+	}
+	deleteRow := func(rs *resultSet) {
+		if rs.selectedRow < 0 || !makeEditable(rs) {
+			return
+		}
+		rs.toggleDelete(rs.selectedRow)
+		rs.refresh()
+		updateEditButtons()
+	}
+	addRowBtn.OnTapped = func() {
+		if rs := activeResult(); rs != nil {
+			addRow(rs)
+		}
+	}
+	duplicateRowBtn.OnTapped = func() {
+		if rs := activeResult(); rs != nil {
+			duplicateRow(rs)
+		}
+	}
+	deleteRowBtn.OnTapped = func() {
+		if rs := activeResult(); rs != nil {
+			deleteRow(rs)
+		}
+	}
+
+	// showCellMenu opens the context menu of a data cell of a browsed table
+	showCellMenu = func(rs *resultSet, id widget.TableCellID, pos fyne.Position) {
+		if id.Row == 0 || rs.source == "" {
+			return
+		}
+		rs.selectedRow = id.Row - 1
+		rs.refresh()
+		updateEditButtons()
+
+		deleteLabel := "Delete Row"
+		if rs.deleted[rs.selectedRow] {
+			deleteLabel = "Undelete Row"
+		}
+		menu := fyne.NewMenu("",
+			fyne.NewMenuItem("Edit Cell…", func() { editCell(rs, cell{id.Row - 1, id.Col}) }),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Add Row", func() { addRow(rs) }),
+			fyne.NewMenuItem("Duplicate Row", func() { duplicateRow(rs) }),
+			fyne.NewMenuItem(deleteLabel, func() { deleteRow(rs) }),
+		)
+		widget.ShowPopUpMenuAtPosition(menu, w.Canvas(), pos)
+	}
+
+	// saveChanges runs the pending changes of rs in one transaction. Rows
+	// added or deleted make the browsed table reload.
+	saveChanges := func(rs *resultSet, changes []db.Change) {
+		if queryBusy() {
+			return
+		}
+		ctx, cancel := db.WithTimeout(context.Background(), queryTimeout)
+		done := make(chan struct{})
+		stopQuery, queryDone, stopRequested = cancel, done, false
+		setRunning(true)
+		status.SetText(fmt.Sprintf("⏳ Saving %d change(s)…", len(changes)))
+
+		go func() {
+			defer close(done)
+			defer cancel()
+
+			err := sess.Apply(ctx, changes)
+			fyne.Do(func() {
+				if closed {
+					return
+				}
+				setRunning(false)
+				if err != nil {
+					if stopRequested && !db.ConnLost(err) {
+						status.SetText("🟡 Connected | Saving cancelled")
+						return
+					}
+					showQueryError(err)
+					return
+				}
+				reload := rs.reshaped() && rs.source == currentTable
+				rs.commitEdits()
+				rs.refresh()
+				updateEditButtons()
+				if reload {
+					runStatements(db.SplitScript(browseQuery, dialect.Syntax()), browseParams)
+				}
+				if sess.InTx() {
+					pendingStatements += len(changes)
+					updateTxToolbar()
+					status.SetText(fmt.Sprintf("🟠 Ran %d change(s) in the open transaction; Commit to keep them", len(changes)))
+					return
+				}
+				status.SetText(fmt.Sprintf("🟢 Connected | Saved %d change(s)", len(changes)))
+			})
+		}()
+	}
+	saveChangesBtn.OnTapped = func() {
+		rs := activeResult()
+		if rs == nil || rs.pending() == 0 {
+			return
+		}
+		changes := rs.changes(dialect)
+		showChangesPreview(w, changes, func() { saveChanges(rs, changes) })
+	}
+	discardChangesBtn.OnTapped = func() {
+		if rs := activeResult(); rs != nil {
+			rs.discardEdits()
+			rs.refresh()
+		}
+		updateEditButtons()
+	}
+
+	// Make column headers clickable for sorting (defined after run function)
+	onCellSelected = func(rs *resultSet, id widget.TableCellID) {
+		if id.Row == 0 && currentTable != "" && id.Col < len(rs.columnNames) {
+			if queryBusy() {
+				rs.table.UnselectAll()
+				return
 			}
 
-			queryEditorInput.SetText(sqlQuery)
-			runQuery()
+			// Clicked on a header - toggle sort. The sort only changes once
+			// unsaved edits are discarded, so cancelling keeps it as shown.
+			clickedColumn := rs.columnNames[id.Col]
+			discardingEdits(func() {
+				if sortColumn == clickedColumn {
+					// Toggle direction
+					if sortDirection == "ASC" {
+						sortDirection = "DESC"
+					} else {
+						sortDirection = "ASC"
+					}
+				} else {
+					// New column - default to ASC
+					sortColumn = clickedColumn
+					sortDirection = "ASC"
+				}
+
+				// Browse again from the first page in the new order
+				browse(firstPage(), 0)
+			}, nil)
 
 			// Deselect the cell
-			table.UnselectAll()
+			rs.table.UnselectAll()
 		} else if id.Row > 0 {
-			// Clicked on a data row - highlight the entire row
+			// Unselect the cell, so a second click on it selects it again
+			rs.table.Unselect(id)
 			rowIdx := id.Row - 1
-			if selectedRow == rowIdx {
+
+			// A double click edits the cell
+			if rs.lastTap == id && time.Since(rs.lastTapAt) <= fyne.CurrentApp().Driver().DoubleTapDelay() {
+				rs.lastTapAt = time.Time{}
+				editCell(rs, cell{rowIdx, id.Col})
+				return
+			}
+			rs.lastTap, rs.lastTapAt = id, time.Now()
+
+			// Clicked on a data row - highlight the entire row
+			if rs.selectedRow == rowIdx {
 				// Clicking the same row again - deselect it
-				selectedRow = -1
+				rs.selectedRow = -1
 			} else {
 				// Select the new row
-				selectedRow = rowIdx
+				rs.selectedRow = rowIdx
 			}
 			// Refresh the table to update highlighting
-			table.Refresh()
+			rs.refresh()
+			updateEditButtons()
 		}
 	}
 
@@ -565,25 +1300,29 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 			}
 		},
 	)
+	reselecting := false // The list goes back to currentTable
 	tableList.OnSelected = func(id widget.ListItemID) {
 		if id < len(filteredTableNames) {
+			if queryBusy() {
+				tableList.UnselectAll()
+				return
+			}
 			itemName := filteredTableNames[id]
 
 			// Check if we're showing databases or tables
-			if connParams.DBType == "mysql" && connParams.DB == "" {
+			if showingDatabases {
 				// We're showing databases, so switch to that database and show its tables
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				ctx, cancel := db.WithTimeout(context.Background(), limits.MetadataTimeout)
 				defer cancel()
 
-				useQuery := fmt.Sprintf("USE `%s`", itemName)
-				_, err := dbh.ExecContext(ctx, useQuery)
-				if err != nil {
+				if err := sess.UseDatabase(ctx, itemName); err != nil {
 					dialog.ShowError(fmt.Errorf("failed to switch to database %s: %v", itemName, err), w)
 					return
 				}
 
 				// Update connection params to reflect the selected database
 				connParams.DB = itemName
+				showingDatabases = false
 
 				// Update the header to show "TABLES"
 				tablesHeader.SetText("TABLES")
@@ -593,69 +1332,96 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 
 				// Show a success message in the query editor
 				queryEditorInput.SetText(fmt.Sprintf("-- Switched to database: %s\n-- Tables are now listed in the sidebar", itemName))
-			} else {
-				// We're showing tables, generate a SELECT statement
-				currentTable = itemName
-
-				// Update table information display
-				updateTableInfo(itemName)
-
-				// Try to find a sort column: primary key first, then fall back to common names
-				sortCol := getPrimaryKeyColumn(itemName)
-
-				// If no primary key found, we'll just not sort
-				// (We could try common column names, but that can fail if they don't exist)
-
-				sortColumn = sortCol
-				sortDirection = "ASC"
-
-				// Generate query with ORDER BY
-				var sqlQuery string
-				if connParams.DBType == "mysql" {
-					if sortCol != "" {
-						sqlQuery = fmt.Sprintf("SELECT * FROM `%s` ORDER BY `%s` ASC LIMIT 100;", itemName, sortCol)
-					} else {
-						sqlQuery = fmt.Sprintf("SELECT * FROM `%s` LIMIT 100;", itemName)
+			} else if !reselecting {
+				// We're showing tables, generate a SELECT statement. The
+				// table only changes once unsaved edits of the current one
+				// are discarded; keeping them selects it again.
+				discardingEdits(func() {
+					currentTable = itemName
+
+					// Update table information display
+					updateTableInfo(itemName)
+
+					// Sort by the primary key, which also pages by keyset. Tables
+					// without one are left unsorted and paged by OFFSET.
+					tableKey, _ = primaryKey(itemName)
+					sortColumn = ""
+					if len(tableKey) > 0 {
+						sortColumn = tableKey[0]
 					}
-					queryEditorInput.SetText(sqlQuery)
-					runQuery()
-				} else { // PostgreSQL
-					if sortCol != "" {
-						sqlQuery = fmt.Sprintf("SELECT * FROM \"%s\" ORDER BY \"%s\" ASC LIMIT 100;", itemName, sortCol)
+					sortDirection = "ASC"
+
+					tableFilter = savedFilter(itemName)
+					filters.SetFilter(tableFilter)
+					browse(firstPage(), 0)
+				}, func() {
+					reselecting = true
+					defer func() { reselecting = false }()
+					if i := slices.Index(filteredTableNames, currentTable); i >= 0 {
+						tableList.Select(i)
 					} else {
-						sqlQuery = fmt.Sprintf("SELECT * FROM \"%s\" LIMIT 100;", itemName)
+						tableList.UnselectAll()
 					}
-					queryEditorInput.SetText(sqlQuery)
-					runQuery()
-				}
+				})
 			}
 		}
 	}
 
-	// Buttons
-	runBtn := widget.NewButton("▶ Run Query", nil)
-	runBtn.Importance = widget.HighImportance
-
-	disconnectBtn := widget.NewButton("Disconnect", func() {
-		if dbh != nil {
-			_ = dbh.Close()
+	var shortcuts []*desktop.CustomShortcut // Set below
+	disconnect := func() {
+		closed = true
+		for _, sc := range shortcuts {
+			w.Canvas().RemoveShortcut(sc)
+		}
+		if stopQuery != nil {
+			stopRequested = true
+			stopQuery()
 		}
+		_ = sess.Close()
+		_ = dbh.Close()
 		_ = closer()
 		onDisconnect()
+	}
+	disconnectBtn := widget.NewButton("Disconnect", func() {
+		if !sess.InTx() {
+			disconnect()
+			return
+		}
+		dialog.ShowConfirm("Uncommitted Transaction",
+			fmt.Sprintf("The open transaction has %d pending statement(s).\n"+
+				"Disconnecting rolls them back. Disconnect anyway?", pendingStatements),
+			func(ok bool) {
+				if ok {
+					disconnect()
+				}
+			}, w)
 	})
 
 	runBtn.OnTapped = runQuery
 
-	// Keyboard shortcuts
-	s := &desktop.CustomShortcut{
-		KeyName:  fyne.KeyReturn,
-		Modifier: fyne.KeyModifierSuper,
+	// Past queries are inserted on a line of their own, or run as they are
+	historyBtn.OnTapped = func() {
+		showHistory(w, connName, func(query string) {
+			if text := queryEditorInput.Text; text != "" && !strings.HasSuffix(text, "\n") {
+				query = "\n" + query
+			}
+			queryEditorInput.Append(query + ";\n")
+		}, func(query string) {
+			runStatements(db.SplitScript(query, dialect.Syntax()), nil)
+		})
+	}
+
+	// Keyboard shortcuts, Cmd on macOS and Ctrl elsewhere. They work anywhere
+	// in the window; the editor takes them itself while focused.
+	shortcuts = []*desktop.CustomShortcut{
+		{KeyName: fyne.KeyReturn, Modifier: fyne.KeyModifierShortcutDefault},
+		{KeyName: fyne.KeyReturn, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierAlt},
+		{KeyName: fyne.KeyReturn, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift},
+	}
+	for i, fn := range []func(){runCurrent, runSelection, runQuery} {
+		queryEditorInput.AddShortcut(shortcuts[i], fn)
+		w.Canvas().AddShortcut(shortcuts[i], func(fyne.Shortcut) { fn() })
 	}
-	w.Canvas().AddShortcut(s, func(sc fyne.Shortcut) {
-		if w.Canvas().Focused() == queryEditorInput {
-			runQuery()
-		}
-	})
 
 	// Initial fetch of tables/databases
 	fetchTables()
@@ -680,6 +1446,13 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 	queryToolbar := container.NewHBox(
 		queryHeader,
 		layout.NewSpacer(),
+		txStatus,
+		commitBtn,
+		rollbackBtn,
+		beginTxBtn,
+		onErrorSelect,
+		historyBtn,
+		stopBtn,
 		runBtn,
 	)
 
@@ -689,11 +1462,21 @@ func ShowMainInterface(w fyne.Window, dbh *sql.DB, closer func() error, connPara
 	resultsHeader := widget.NewLabel("Query Results")
 	resultsHeader.TextStyle = fyne.TextStyle{Monospace: true}
 
-	resultsArea := container.NewBorder(
+	resultsToolbar := container.NewHBox(
 		resultsHeader,
-		status,
+		layout.NewSpacer(),
+		addRowBtn,
+		duplicateRowBtn,
+		deleteRowBtn,
+		discardChangesBtn,
+		saveChangesBtn,
+	)
+
+	resultsArea := container.NewBorder(
+		container.NewVBox(resultsToolbar, filters.content),
+		container.NewVBox(pager, status),
 		nil, nil,
-		table, // Table widget has built-in scrolling with fixed headers
+		resultTabs, // Table widgets have built-in scrolling with fixed headers
 	)
 
 	// Main content area
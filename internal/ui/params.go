@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pn/kymar/internal/config"
+	"github.com/pn/kymar/internal/db"
+)
+
+// showParamsDialog asks for the values of the query parameters names, filled
+// in with the values last used for them, and passes them to onRun ready to
+// bind. The entered values are remembered in the config.
+func showParamsDialog(w fyne.Window, names []string, onRun func(values map[string]any)) {
+	// Don't overwrite a config that failed to load
+	cfg, err := config.Load()
+	remember := err == nil
+	if !remember {
+		cfg = &config.Config{}
+	}
+
+	types := make([]string, len(db.ParamTypes))
+	for i, t := range db.ParamTypes {
+		types[i] = string(t)
+	}
+
+	var items []*widget.FormItem
+	entries := make([]*widget.Entry, len(names))
+	typeSelects := make([]*widget.Select, len(names))
+	for i, name := range names {
+		last, ok := cfg.Variables[name]
+		if !ok {
+			last = db.ParamValue{Type: db.ParamString}
+		}
+
+		entry := widget.NewEntry()
+		entry.SetText(last.Text)
+		typeSelect := widget.NewSelect(types, nil)
+		entry.Validator = func(s string) error {
+			_, err := db.ParamValue{Type: db.ParamType(typeSelect.Selected), Text: s}.Value()
+			return err
+		}
+		typeSelect.OnChanged = func(t string) {
+			if db.ParamType(t) == db.ParamNull {
+				entry.Disable()
+			} else {
+				entry.Enable()
+			}
+			entry.Validate()
+		}
+		typeSelect.SetSelected(string(last.Type))
+		if typeSelect.Selected == "" {
+			typeSelect.SetSelected(string(db.ParamString))
+		}
+
+		entries[i], typeSelects[i] = entry, typeSelect
+		items = append(items, widget.NewFormItem(name, container.NewBorder(nil, nil, nil, typeSelect, entry)))
+	}
+
+	d := dialog.NewForm("Query Parameters", "Run", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		entered := make(map[string]db.ParamValue, len(names))
+		values := make(map[string]any, len(names))
+		for i, name := range names {
+			v := db.ParamValue{Type: db.ParamType(typeSelects[i].Selected), Text: entries[i].Text}
+			if v.Type == db.ParamNull {
+				v.Text = ""
+			}
+			value, err := v.Value()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			entered[name], values[name] = v, value
+		}
+		if remember {
+			if err := cfg.SetVariables(entered); err != nil {
+				dialog.ShowError(err, w)
+			}
+		}
+		onRun(values)
+	}, w)
+	d.Resize(fyne.NewSize(460, 0))
+	d.Show()
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pn/kymar/internal/secrets"
+	"github.com/pn/kymar/internal/ssh"
+)
+
+// DialogPrompter answers SSH authentication and vault prompts with modal dialogs.
+// Its methods block, so they must be called off the UI goroutine.
+type DialogPrompter struct {
+	Window fyne.Window
+}
+
+// Passphrase asks for the passphrase of an encrypted private key
+func (p *DialogPrompter) Passphrase(keyFile string) (string, error) {
+	answers, err := p.ask("Key Passphrase", "Enter the passphrase for "+keyFile,
+		[]string{"Passphrase:"}, []bool{false})
+	if err != nil {
+		return "", err
+	}
+	return answers[0], nil
+}
+
+// Challenge answers a keyboard-interactive challenge such as an MFA code
+func (p *DialogPrompter) Challenge(user, instruction string, questions []string, echos []bool) ([]string, error) {
+	title := "SSH Authentication"
+	if user != "" {
+		title += " for " + user
+	}
+	return p.ask(title, instruction, questions, echos)
+}
+
+// ConfirmHostKey asks whether to trust a host seen for the first time
+func (p *DialogPrompter) ConfirmHostKey(host, keyType, fingerprint string) (bool, error) {
+	result := make(chan bool, 1)
+
+	fyne.Do(func() {
+		msg := widget.NewLabel(fmt.Sprintf(
+			"The authenticity of host '%s' can't be established.\n\n"+
+				"%s key fingerprint is:\n%s\n\n"+
+				"Only continue if this matches the fingerprint published by the server's administrator. "+
+				"The key will be saved to ~/.kymar/known_hosts.",
+			host, keyType, fingerprint))
+		msg.Wrapping = fyne.TextWrapWord
+
+		d := dialog.NewCustomConfirm("Unknown SSH Host", "Trust and Connect", "Cancel", msg, func(ok bool) {
+			result <- ok
+		}, p.Window)
+		d.Resize(fyne.NewSize(480, 0))
+		d.Show()
+	})
+
+	return <-result, nil
+}
+
+// MasterPassword asks for the password of the secrets vault. When creating a
+// vault it asks twice and repeats until both entries match.
+func (p *DialogPrompter) MasterPassword(create bool) (string, error) {
+	if !create {
+		answers, err := p.ask("Unlock Vault", "Enter the master password protecting your saved passwords",
+			[]string{"Master password:"}, []bool{false})
+		if err != nil {
+			return "", secrets.ErrCancelled
+		}
+		return answers[0], nil
+	}
+
+	instruction := "Choose a master password to encrypt saved passwords in ~/.kymar/vault.json. It cannot be recovered."
+	for {
+		answers, err := p.ask("Create Vault", instruction,
+			[]string{"Master password:", "Confirm:"}, []bool{false, false})
+		if err != nil {
+			return "", secrets.ErrCancelled
+		}
+		switch {
+		case answers[0] == "":
+			instruction = "The master password must not be empty."
+		case answers[0] != answers[1]:
+			instruction = "The passwords didn't match. Try again."
+		default:
+			return answers[0], nil
+		}
+	}
+}
+
+// ask shows a form with one entry per question and waits for the answers
+func (p *DialogPrompter) ask(title, instruction string, questions []string, echos []bool) ([]string, error) {
+	result := make(chan []string, 1)
+
+	fyne.Do(func() {
+		var items []*widget.FormItem
+		if instruction != "" {
+			lbl := widget.NewLabel(instruction)
+			lbl.Wrapping = fyne.TextWrapWord
+			items = append(items, widget.NewFormItem("", lbl))
+		}
+
+		entries := make([]*widget.Entry, len(questions))
+		for i, q := range questions {
+			if i < len(echos) && echos[i] {
+				entries[i] = widget.NewEntry()
+			} else {
+				entries[i] = widget.NewPasswordEntry()
+			}
+			items = append(items, widget.NewFormItem(q, entries[i]))
+		}
+
+		d := dialog.NewForm(title, "OK", "Cancel", items, func(ok bool) {
+			if !ok {
+				result <- nil
+				return
+			}
+			answers := make([]string, len(entries))
+			for i, e := range entries {
+				answers[i] = e.Text
+			}
+			result <- answers
+		}, p.Window)
+		d.Resize(fyne.NewSize(420, 0))
+		d.Show()
+	})
+
+	answers := <-result
+	if answers == nil {
+		return nil, ssh.ErrCancelled
+	}
+	return answers, nil
+}
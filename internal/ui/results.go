@@ -0,0 +1,355 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"image/color"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pn/kymar/internal/db"
+)
+
+// resultSet is the content of one results tab: the rows returned by a
+// statement, or a message about its outcome
+type resultSet struct {
+	headers     []string // Display headers with types (e.g., "id (BIGINT)")
+	columnNames []string // Column names without types (for queries)
+	rows        [][]string
+	// values holds the rows as the driver returned them, nil for NULL.
+	// Statements about rows bind these rather than the text shown.
+	values      [][]any
+	selectedRow int // Track which row is selected (-1 means none)
+
+	// Editing of rows browsed from a table
+	source  string            // Table the rows come from, "" if not a table browse
+	key     []int             // Column indexes of its primary key, once looked up
+	edits   map[cell]cellEdit // Pending changes
+	added   map[int]bool      // Rows to insert, appended to rows
+	deleted map[int]bool      // Rows to delete
+	// onMenu shows the context menu of a cell at an absolute position
+	onMenu func(id widget.TableCellID, pos fyne.Position)
+	// Last click on a data cell, to detect double clicks
+	lastTap   widget.TableCellID
+	lastTapAt time.Time
+
+	table *widget.Table // Created on the UI goroutine by newTable
+}
+
+func newResultSet() *resultSet {
+	return &resultSet{selectedRow: -1}
+}
+
+// setMessage replaces the content with a single "Result" cell
+func (rs *resultSet) setMessage(msg string) {
+	rs.columnNames = nil
+	rs.headers = []string{"Result"}
+	rs.rows = [][]string{{msg}}
+	rs.values = [][]any{{msg}}
+	rs.selectedRow = -1
+}
+
+// newTable creates the table showing rs. headerSuffix may decorate a column
+// header, e.g. with a sort indicator.
+func (rs *resultSet) newTable(headerSuffix func(col int) string) *widget.Table {
+	table := widget.NewTable(
+		func() (int, int) {
+			if len(rs.headers) == 0 {
+				return 1, 1
+			}
+			return len(rs.rows) + 1, len(rs.headers)
+		},
+		func() fyne.CanvasObject {
+			return newResultCell(rs)
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			c := o.(*resultCell)
+			c.id = id
+			bg, lbl := c.bg, c.label
+
+			if id.Row == 0 {
+				// Header row styling
+				if id.Col < len(rs.headers) {
+					lbl.SetText(rs.headers[id.Col] + headerSuffix(id.Col))
+				} else {
+					lbl.SetText("")
+				}
+				lbl.TextStyle = fyne.TextStyle{Monospace: true}
+				lbl.Alignment = fyne.TextAlignCenter
+				lbl.Wrapping = fyne.TextTruncate
+				bg.FillColor = color.Transparent
+				bg.Refresh()
+				return
+			}
+			// Data rows
+			rowIdx := id.Row - 1
+			if rowIdx < len(rs.rows) && id.Col < len(rs.rows[rowIdx]) {
+				lbl.TextStyle = fyne.TextStyle{Monospace: true}
+				lbl.Alignment = fyne.TextAlignLeading
+				lbl.Wrapping = fyne.TextTruncate
+
+				// Pending changes show their new value on a marker
+				e, edited := rs.edits[cell{rowIdx, id.Col}]
+				if edited {
+					lbl.SetText(e.text())
+				} else {
+					lbl.SetText(rs.rows[rowIdx][id.Col])
+				}
+
+				// Mark pending changes, else highlight the entire row if
+				// this row is selected
+				switch {
+				case rs.deleted[rowIdx]:
+					bg.FillColor = deletedColor
+				case edited:
+					bg.FillColor = editedColor
+				case rs.added[rowIdx]:
+					bg.FillColor = addedColor
+				case rowIdx == rs.selectedRow:
+					// Use a vivid, prominent highlight color like TablePlus
+					bg.FillColor = color.RGBA{R: 0, G: 115, B: 230, A: 255} // Solid blue highlight
+				default:
+					bg.FillColor = color.Transparent
+				}
+				bg.Refresh()
+			}
+		},
+	)
+
+	// Set the header row to be sticky (non-scrolling)
+	table.StickyRowCount = 1
+	table.StickyColumnCount = 0
+
+	rs.table = table
+	return table
+}
+
+// resultCell is a cell of a results table: a label on a background. It
+// takes the clicks on the cell, so it passes them on to the table and
+// opens the context menu on secondary clicks.
+type resultCell struct {
+	widget.BaseWidget
+	bg    *canvas.Rectangle
+	label *widget.Label
+	id    widget.TableCellID
+	rs    *resultSet
+}
+
+func newResultCell(rs *resultSet) *resultCell {
+	lbl := widget.NewLabel("")
+	lbl.Wrapping = fyne.TextTruncate
+	c := &resultCell{bg: canvas.NewRectangle(color.Transparent), label: lbl, rs: rs}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *resultCell) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(c.bg, c.label))
+}
+
+// Tapped selects the cell
+func (c *resultCell) Tapped(*fyne.PointEvent) {
+	c.rs.table.Select(c.id)
+}
+
+// TappedSecondary opens the context menu of the cell
+func (c *resultCell) TappedSecondary(e *fyne.PointEvent) {
+	if c.rs.onMenu != nil {
+		c.rs.onMenu(c.id, e.AbsolutePosition)
+	}
+}
+
+// setupColumns sets intelligent column widths based on content
+func (rs *resultSet) setupColumns() {
+	for i, header := range rs.headers {
+		// Calculate width based on header and content
+		minWidth := float32(100) // Minimum 100px
+		maxWidth := float32(300) // Maximum 300px for readability
+
+		// Base width on header length
+		headerWidth := float32(len(header) * 8) // ~8px per character
+
+		// Check first few rows for content width
+		contentWidth := headerWidth
+		checkRows := min(len(rs.rows), 10) // Only check first 10 rows for performance
+
+		for j := 0; j < checkRows; j++ {
+			if i < len(rs.rows[j]) {
+				cellWidth := float32(len(rs.rows[j][i]) * 7) // ~7px per character for data
+				if cellWidth > contentWidth {
+					contentWidth = cellWidth
+				}
+			}
+		}
+
+		// Set width with min/max bounds
+		width := min(max(contentWidth+20, minWidth), maxWidth) // Add padding
+		rs.table.SetColumnWidth(i, width)
+	}
+}
+
+// refresh redraws the table after the content changed
+func (rs *resultSet) refresh() {
+	rs.table.Refresh()
+}
+
+// streamRows runs query with args on q and streams its rows into rs a few times a
+// second, counting them in fetched. Each further result set, e.g. of a stored
+// procedure, goes into the result set returned by more. A result set is cut
+// after maxRows rows when maxRows is positive: stop, if not nil, is called to
+// end the query instead of reading the rest, and later result sets are
+// skipped. streamRows reports whether a result was cut. It runs off the UI
+// goroutine and touches result sets only through fyne.Do.
+func streamRows(ctx context.Context, q db.Queryer, query string, args []any, rs *resultSet, more func() *resultSet, maxRows int, stop func(), fetched *atomic.Int64) (bool, error) {
+	r, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	for {
+		cut, err := streamResult(r, rs, maxRows, fetched)
+		if cut {
+			if stop != nil {
+				stop()
+			}
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !r.NextResultSet() {
+			return false, r.Err()
+		}
+		rs = more()
+	}
+}
+
+// streamResult streams the current result set of r into rs
+func streamResult(r *sql.Rows, rs *resultSet, maxRows int, fetched *atomic.Int64) (bool, error) {
+	names, hdrs, err := resultColumns(r)
+	if err != nil {
+		return false, err
+	}
+	if len(names) == 0 {
+		// Statements like CALL or PRAGMA may turn out to return nothing
+		fyne.Do(func() {
+			rs.setMessage("OK")
+			rs.refresh()
+			rs.setupColumns()
+		})
+		return false, nil
+	}
+	fyne.Do(func() {
+		rs.columnNames, rs.headers = names, hdrs
+		rs.refresh()
+	})
+
+	// Stream rows into the model in batches
+	reader := newRowReader(len(names))
+	var batch [][]string
+	var batchValues [][]any
+	flushed, first := time.Now(), true
+	flush := func() {
+		b, bv, sizeColumns := batch, batchValues, first
+		batch, batchValues, flushed, first = nil, nil, time.Now(), false
+		fyne.Do(func() {
+			rs.rows = append(rs.rows, b...)
+			rs.values = append(rs.values, bv...)
+			rs.refresh()
+			if sizeColumns {
+				rs.setupColumns()
+			}
+		})
+	}
+	defer flush()
+
+	count := 0
+	for r.Next() {
+		if maxRows > 0 && count >= maxRows {
+			return true, nil
+		}
+		row, values, err := reader.read(r)
+		if err != nil {
+			return false, err
+		}
+		batch = append(batch, row)
+		batchValues = append(batchValues, values)
+		count++
+		fetched.Add(1)
+		if time.Since(flushed) >= 250*time.Millisecond {
+			flush()
+		}
+	}
+	return false, r.Err()
+}
+
+// resultColumns returns the column names of r and display headers with types
+func resultColumns(r *sql.Rows) ([]string, []string, error) {
+	colTypes, err := r.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columnNames := make([]string, len(colTypes))
+	headers := make([]string, len(colTypes))
+	for i, col := range colTypes {
+		columnNames[i] = col.Name()
+		headers[i] = fmt.Sprintf("%s (%s)", col.Name(), col.DatabaseTypeName())
+	}
+	return columnNames, headers, nil
+}
+
+// rowReader scans result rows, reusing its scan arguments between rows
+type rowReader struct {
+	scanArgs []any
+}
+
+func newRowReader(columns int) *rowReader {
+	rr := &rowReader{scanArgs: make([]any, columns)}
+	for i := range rr.scanArgs {
+		rr.scanArgs[i] = new(any)
+	}
+	return rr
+}
+
+// read scans the current row of r, returning its values as text and as the
+// driver returned them. NULLs are shown as "NULL".
+func (rr *rowReader) read(r *sql.Rows) ([]string, []any, error) {
+	if err := r.Scan(rr.scanArgs...); err != nil {
+		return nil, nil, err
+	}
+	out := make([]string, len(rr.scanArgs))
+	values := make([]any, len(rr.scanArgs))
+	for i, arg := range rr.scanArgs {
+		values[i] = *arg.(*any)
+		out[i] = formatValue(values[i])
+	}
+	return out, values, nil
+}
+
+// formatValue returns the text shown for a value returned by a driver
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return fmt.Sprint(v)
+}